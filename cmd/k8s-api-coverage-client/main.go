@@ -23,11 +23,13 @@ import (
 	"path"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"knative.dev/test-infra/shared/prow"
 	"sigs.k8s.io/k8s-api-coverage/pkg/common"
 	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 	"sigs.k8s.io/k8s-api-coverage/pkg/kube"
 	"sigs.k8s.io/k8s-api-coverage/pkg/tools"
+	"sigs.k8s.io/k8s-api-coverage/pkg/webhook"
 )
 
 var (
@@ -39,7 +41,7 @@ var (
 func getFailedResourceCoverages() coveragecalculator.CoveragePercentages {
 	percentCoverages := make(map[string]float64)
 	for resourceKind := range common.ResourceMap {
-		percentCoverages[resourceKind.Kind] = 0.0
+		percentCoverages[webhook.ResourceKey(resourceKind)] = 0.0
 	}
 	percentCoverages["Overall"] = 0.0
 	return coveragecalculator.CoveragePercentages{
@@ -70,31 +72,85 @@ func main() {
 		return
 	}
 
+	if flag.Arg(0) == "replay" {
+		replay(artifactsDir, flag.Args()[1:])
+		return
+	}
+
 	webhookURI := getWebhookURI()
 	log.Printf("Using webhook-uri %s", webhookURI)
 
-	for gvk := range common.ResourceMap {
-		outputPath := path.Join(artifactsDir, strings.ToLower(gvk.Group)+"_"+strings.ToLower(gvk.Version)+"_"+strings.ToLower(gvk.Kind)+".html")
+	// The webhook may have been setup with a different set of resources than
+	// common.ResourceMap (eg: discovered from the cluster), so list its own
+	coverage, err := tools.GetResourcePercentages(webhookURI)
+	if err != nil {
+		log.Fatalf("Failed retrieving resource coverage percentages: %v", err)
+	}
+
+	for key := range coverage.ResourceCoverages {
+		if key == "Overall" {
+			continue
+		}
+		gvk := webhook.ParseResourceKey(key)
+		outputPath := resourceCoverageOutputPath(artifactsDir, gvk)
 		err := tools.GetAndWriteResourceCoverage(webhookURI, gvk, outputPath)
 		if err != nil {
 			log.Printf("Failed retrieving resource coverage for resource %v: %v ", gvk, err)
 		} else {
 			log.Printf("Wrote resource coverage for resource %v to %s", gvk, outputPath)
 		}
+
+		outputPath = resourcePathCoverageOutputPath(artifactsDir, gvk)
+		err = tools.GetAndWriteResourcePathCoverage(webhookURI, gvk, outputPath)
+		if err != nil {
+			log.Printf("Failed retrieving resource path coverage for resource %v: %v ", gvk, err)
+		} else {
+			log.Printf("Wrote resource path coverage for resource %v to %s", gvk, outputPath)
+		}
+	}
+
+	for key := range coverage.SubresourceCoverages {
+		subresource, ok := webhook.ParseSubresourceKey(key)
+		if !ok {
+			log.Printf("Skipping coverage for unknown subresource %s", key)
+			continue
+		}
+		outputPath := subresourceCoverageOutputPath(artifactsDir, subresource)
+		err := tools.GetAndWriteSubresourceCoverage(webhookURI, subresource, outputPath)
+		if err != nil {
+			log.Printf("Failed retrieving subresource coverage for subresource %s: %v ", key, err)
+		} else {
+			log.Printf("Wrote subresource coverage for subresource %s to %s", key, outputPath)
+		}
+	}
+
+	for key := range coverage.OptionsCoverages {
+		gvk := webhook.ParseResourceKey(key)
+		outputPath := resourceCoverageOutputPath(artifactsDir, gvk)
+		err := tools.GetAndWriteResourceCoverage(webhookURI, gvk, outputPath)
+		if err != nil {
+			log.Printf("Failed retrieving options coverage for options %v: %v ", gvk, err)
+		} else {
+			log.Printf("Wrote options coverage for options %v to %s", gvk, outputPath)
+		}
+
+		outputPath = resourcePathCoverageOutputPath(artifactsDir, gvk)
+		err = tools.GetAndWriteResourcePathCoverage(webhookURI, gvk, outputPath)
+		if err != nil {
+			log.Printf("Failed retrieving options path coverage for options %v: %v ", gvk, err)
+		} else {
+			log.Printf("Wrote options path coverage for options %v to %s", gvk, outputPath)
+		}
 	}
 
 	outputPath := path.Join(artifactsDir, "totalcoverage.html")
-	err := tools.GetAndWriteTotalCoverage(webhookURI, outputPath)
+	err = tools.GetAndWriteTotalCoverage(webhookURI, outputPath)
 	if err != nil {
 		log.Fatalf("total coverage retrieval failed: %v", err)
 	}
 	log.Printf("Wrote resource coverage percentages to %s", outputPath)
 
 	outputPath = path.Join(artifactsDir, "junit_bazel.xml")
-	coverage, err := tools.GetResourcePercentages(webhookURI)
-	if err != nil {
-		log.Fatalf("Failed retrieving resource coverage percentages: %v", err)
-	}
 	err = tools.WriteResourcePercentages(outputPath, coverage)
 	if err != nil {
 		log.Fatalf("Failed writing resource coverage percentages: %v", err)
@@ -102,6 +158,19 @@ func main() {
 	log.Printf("Wrote resource coverage percentages to %s", outputPath)
 }
 
+func resourceCoverageOutputPath(artifactsDir string, gvk schema.GroupVersionKind) string {
+	return path.Join(artifactsDir, strings.ToLower(gvk.Group)+"_"+strings.ToLower(gvk.Version)+"_"+strings.ToLower(gvk.Kind)+".html")
+}
+
+func resourcePathCoverageOutputPath(artifactsDir string, gvk schema.GroupVersionKind) string {
+	return strings.TrimSuffix(resourceCoverageOutputPath(artifactsDir, gvk), ".html") + "_paths.html"
+}
+
+func subresourceCoverageOutputPath(artifactsDir string, subresource webhook.Subresource) string {
+	gvr := subresource.Resource
+	return path.Join(artifactsDir, strings.ToLower(gvr.Group)+"_"+strings.ToLower(gvr.Version)+"_"+gvr.Resource+"_"+subresource.Subresource+".html")
+}
+
 func getWebhookURI() string {
 	if *webhookURIFlag != "" {
 		return *webhookURIFlag
@@ -0,0 +1,177 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"container/list"
+	"flag"
+	"log"
+	"os"
+	"path"
+	"strings"
+
+	"go.uber.org/zap"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/k8s-api-coverage/pkg/common"
+	"sigs.k8s.io/k8s-api-coverage/pkg/resourcetree"
+	"sigs.k8s.io/k8s-api-coverage/pkg/rules"
+	"sigs.k8s.io/k8s-api-coverage/pkg/tools"
+	"sigs.k8s.io/k8s-api-coverage/pkg/webhook"
+)
+
+var (
+	replayFlags       = flag.NewFlagSet("replay", flag.ExitOnError)
+	auditLogFlag      = replayFlags.String("audit-log", "", "path of a kube-apiserver audit log, in json format, to compute coverage from")
+	ignoredFieldsFlag = replayFlags.String("ignored-fields", "ignoredfields.yaml", "path of the .yaml file listing fields to be ignored")
+	crdsFlag          = replayFlags.String("crds", "", "comma separated paths of manifests whose CustomResourceDefinitions to also compute coverage of")
+	allTypesFlag      = replayFlags.Bool("all-types", false, "count the fields of every type reachable from resources, not only those of types that were covered, so coverage percentages have a stable denominator")
+)
+
+// replay computes coverage from an audit log on disk instead of asking the
+// webhook, and writes the same artifacts
+func replay(artifactsDir string, args []string) {
+	replayFlags.Parse(args)
+	if *auditLogFlag == "" {
+		log.Fatal("replay requires -audit-log")
+	}
+
+	auditLog, err := os.Open(*auditLogFlag)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer auditLog.Close()
+
+	schemaMap := readSchemaMap(*crdsFlag)
+
+	// The recorder logs every resource it records at info level, which is
+	// far too chatty for an audit log from a whole test run
+	loggingConfig := zap.NewProductionConfig()
+	loggingConfig.Level = zap.NewAtomicLevelAt(zap.WarnLevel)
+	logger, err := loggingConfig.Build()
+	if err != nil {
+		log.Fatalf("Failed to build logger: %v", err)
+	}
+
+	recorder := webhook.APICoverageRecorder{
+		Logger: logger.Sugar().Named("replay"),
+		ResourceForest: resourcetree.ResourceForest{
+			Version:         "v1alpha1",
+			ConnectedNodes:  make(map[string]*list.List),
+			TopLevelTrees:   make(map[string]resourcetree.ResourceTree),
+			ExpectedMapKeys: rules.ExpectedMapKeys,
+		},
+		ResourceMap:       common.ResourceMap,
+		SchemaMap:         schemaMap,
+		SubresourceMap:    common.SubresourceMap,
+		OptionsMap:        common.OptionsMap,
+		NodeRules:         rules.NodeRules,
+		FieldRules:        rules.FieldRules,
+		DisplayRules:      rules.GetDisplayRules(),
+		IgnoredFieldsFile: *ignoredFieldsFlag,
+		AllTypes:          *allTypesFlag,
+	}
+	recorder.Init()
+
+	recorded, err := recorder.ReplayAuditLog(auditLog)
+	if err != nil {
+		log.Fatalf("Failed replaying audit log %s: %v", *auditLogFlag, err)
+	}
+	log.Printf("Recorded coverage from %d audit events in %s", recorded, *auditLogFlag)
+
+	gvks := []schema.GroupVersionKind{}
+	for gvk := range common.ResourceMap {
+		gvks = append(gvks, gvk)
+	}
+	for gvk := range schemaMap {
+		gvks = append(gvks, gvk)
+	}
+	for gvk := range common.OptionsMap {
+		gvks = append(gvks, gvk)
+	}
+	for _, gvk := range gvks {
+		outputPath := resourceCoverageOutputPath(artifactsDir, gvk)
+		coverageValues, typeCoverage := recorder.BuildResourceCoverage(webhook.ResourceKey(gvk), resourcetree.CoverageOptions{})
+		err := tools.WriteResourceCoverage(outputPath, typeCoverage, coverageValues)
+		if err != nil {
+			log.Printf("Failed writing resource coverage for resource %v: %v ", gvk, err)
+		} else {
+			log.Printf("Wrote resource coverage for resource %v to %s", gvk, outputPath)
+		}
+
+		outputPath = resourcePathCoverageOutputPath(artifactsDir, gvk)
+		coverageValues, typeCoverage = recorder.BuildResourcePathCoverage(webhook.ResourceKey(gvk), resourcetree.CoverageOptions{})
+		err = tools.WriteResourceCoverage(outputPath, typeCoverage, coverageValues)
+		if err != nil {
+			log.Printf("Failed writing resource path coverage for resource %v: %v ", gvk, err)
+		} else {
+			log.Printf("Wrote resource path coverage for resource %v to %s", gvk, outputPath)
+		}
+	}
+
+	for subresource := range common.SubresourceMap {
+		outputPath := subresourceCoverageOutputPath(artifactsDir, subresource)
+		coverageValues, typeCoverage := recorder.BuildResourceCoverage(webhook.SubresourceKey(subresource), resourcetree.CoverageOptions{})
+		err := tools.WriteResourceCoverage(outputPath, typeCoverage, coverageValues)
+		if err != nil {
+			log.Printf("Failed writing subresource coverage for subresource %s: %v ", webhook.SubresourceKey(subresource), err)
+		} else {
+			log.Printf("Wrote subresource coverage for subresource %s to %s", webhook.SubresourceKey(subresource), outputPath)
+		}
+	}
+
+	outputPath := path.Join(artifactsDir, "totalcoverage.html")
+	err = tools.WriteTotalCoverage(outputPath, recorder.BuildTotalCoverage(resourcetree.CoverageOptions{}))
+	if err != nil {
+		log.Fatalf("Failed writing total coverage: %v", err)
+	}
+	log.Printf("Wrote total coverage to %s", outputPath)
+
+	outputPath = path.Join(artifactsDir, "junit_bazel.xml")
+	err = tools.WriteResourcePercentages(outputPath, recorder.BuildResourceCoveragePercentages())
+	if err != nil {
+		log.Fatalf("Failed writing resource coverage percentages: %v", err)
+	}
+	log.Printf("Wrote resource coverage percentages to %s", outputPath)
+}
+
+// readSchemaMap returns the schemas of the CustomResourceDefinitions in a comma
+// separated list of manifests.
+func readSchemaMap(paths string) map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps {
+	schemaMap := make(map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps)
+	if paths == "" {
+		return schemaMap
+	}
+	for _, crdPath := range strings.Split(paths, ",") {
+		manifest, err := os.Open(crdPath)
+		if err != nil {
+			log.Fatalf("Failed to open CustomResourceDefinitions: %v", err)
+		}
+		crds, err := common.ReadCRDs(manifest)
+		manifest.Close()
+		if err != nil {
+			log.Fatalf("Failed reading CustomResourceDefinitions from %s: %v", crdPath, err)
+		}
+		for _, crd := range crds {
+			for gvk, crdSchema := range common.SchemaMapForCRD(crd) {
+				schemaMap[gvk] = crdSchema
+			}
+		}
+	}
+	log.Printf("Read the schemas of %d custom resources from %s", len(schemaMap), paths)
+	return schemaMap
+}
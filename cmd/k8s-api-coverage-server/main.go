@@ -18,109 +18,116 @@ package main
 
 import (
 	"container/list"
+	"flag"
 	"log"
 	"net/http"
 	"net/http/pprof"
+	"strings"
+	"time"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/signals"
+	"sigs.k8s.io/k8s-api-coverage/pkg/checkpoint"
 	"sigs.k8s.io/k8s-api-coverage/pkg/common"
+	"sigs.k8s.io/k8s-api-coverage/pkg/kube"
 	"sigs.k8s.io/k8s-api-coverage/pkg/resourcetree"
 	"sigs.k8s.io/k8s-api-coverage/pkg/rules"
 	"sigs.k8s.io/k8s-api-coverage/pkg/webhook"
 )
 
-// TODO(spiffxp): I don't think GVK is plumbed all the way through, and the words
-// resource and kind are used interchangeably here, where I notice they mean subtly
-// different things in apimachinery docs
-
-// TODO(spiffxp): the total number of fields to cover grows as the cluster is
-// exercised; why isn't resourcetree seeing all of these fields to begin with?
-// BECAUSE they are pointers, and it won't traverse nil pointers
-
-// TODO(spiffxp): if the container dies, the pod will crashloopbackoff because this
-// refuses to come back up because it fails on webhook registration; I think this
-// should either ignore, ignore if hook ownerref is the same as what this would set,
-// or delete/recreate. Either way, coverage state isn't preserved across restarts,
-// does it make sense to try and persist?
-
-// TODO(spiffxp): Admission webhooks don't get access to user agent. It's not
-// clear to me how else to plumb through which test is exercising a given
-// resource/field. There is userInfo but that would require a different user
-// for each test I think. The other alternative is to rewrite this to receive
-// audit log webhooks, although they are still in alpha so not dynamically
-// configurable by default
+var (
+	checkpointStoreFlag    = flag.String("checkpoint-store", "", "where to checkpoint coverage state so it survives restarts, one of file:<path>, configmap:<name> or secret:<name> (default: no checkpoints)")
+	checkpointIntervalFlag = flag.Duration("checkpoint-interval", time.Minute, "how often to checkpoint coverage state")
+	discoveryFlag          = flag.Bool("discovery", false, "record coverage of the resources the API server serves, including custom resources, instead of a hardcoded set of v1 groups")
+	includeGroupsFlag      = flag.String("include-groups", "", "comma separated API groups to record coverage of, \""+common.CoreGroupName+"\" for the legacy group (default: all)")
+	excludeGroupsFlag      = flag.String("exclude-groups", "", "comma separated API groups not to record coverage of")
+	includeVersionsFlag    = flag.String("include-versions", "", "comma separated API versions to record coverage of (default: all)")
+	excludeVersionsFlag    = flag.String("exclude-versions", "", "comma separated API versions not to record coverage of")
+	includeKindsFlag       = flag.String("include-kinds", "", "comma separated kinds to record coverage of (default: all)")
+	excludeKindsFlag       = flag.String("exclude-kinds", "Event", "comma separated kinds not to record coverage of")
+	allTypesFlag           = flag.Bool("all-types", false, "count the fields of every type reachable from resources, not only those of types that were covered, so coverage percentages have a stable denominator")
+)
 
-// TODO(spiffxp): I don't think subresources are getting covered, eg: I'm tailing logs but
-// that option isn't showing up in artifacts/_v1_podlogoptions.html
+// TODO(spiffxp): the words resource and kind are used interchangeably here, where
+// I notice they mean subtly different things in apimachinery docs
 
-// TODO(spiffxp): fatal error: concurrent map iteration and map write
-// soooo I guess I can't update coverage artifacts locally as tests are going on
-/*
-goroutine 8701 [running]:
-runtime.throw(0x13098f3, 0x26)
-	/usr/local/go/src/runtime/panic.go:617 +0x72 fp=0xc00011f2c0 sp=0xc00011f290 pc=0x42dc72
-runtime.mapiternext(0xc00011f380)
-	/usr/local/go/src/runtime/map.go:860 +0x597 fp=0xc00011f348 sp=0xc00011f2c0 pc=0x4107f7
-k8s.io/apimachinery/pkg/util/sets.String.Union(0xc0014580c0, 0xc000ea3e30, 0x3)
-	/go/pkg/mod/k8s.io/apimachinery@v0.0.0-20190817020851-f2f3a405f61d/pkg/util/sets/string.go:115 +0x190 fp=0xc00011f3f0 sp=0xc00011f348 pc=0x798090
-sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator.(*FieldCoverage).Merge(...)
-	/go/src/app/pkg/coveragecalculator/coveragedata.go:37
-sigs.k8s.io/k8s-api-coverage/pkg/resourcetree.(*ResourceForest).getConnectedNodeCoverage(0xc0003cbe08, 0x14fc3e0, 0x12b3940, 0x1ed89c0, 0x1, 0x1, 0xc001424a50, 0x0, 0x0, 0x0, ...)
-	/go/src/app/pkg/resourcetree/resourceforest.go:74 +0x3c1 fp=0xc00011f620 sp=0xc00011f3f0 pc=0xb5b0b1
-sigs.k8s.io/k8s-api-coverage/pkg/resourcetree.(*StructKindNode).buildCoverageData(0xc000800420, 0xc00148e9a0, 0x1ed89b8, 0x1, 0x1, 0x1ed89c0, 0x1, 0x1, 0xc001424a50, 0xc001462660)
-	/go/src/app/pkg/resourcetree/structkindnode.go:91 +0xaa fp=0xc00011f838 sp=0xc00011f620 pc=0xb5c6ca
-sigs.k8s.io/k8s-api-coverage/pkg/resourcetree.(*StructKindNode).buildCoverageData(0xc000800360, 0xc00148e9a0, 0x1ed89b8, 0x1, 0x1, 0x1ed89c0, 0x1, 0x1, 0xc001424a50, 0xc001462660)
-	/go/src/app/pkg/resourcetree/structkindnode.go:101 +0x51a fp=0xc00011fa50 sp=0xc00011f838 pc=0xb5cb3a
-sigs.k8s.io/k8s-api-coverage/pkg/resourcetree.(*ResourceTree).BuildCoverageData(0xc00011fbf0, 0x1ed89b8, 0x1, 0x1, 0x1ed89c0, 0x1, 0x1, 0xc001424a50, 0xc001462630, 0x1, ...)
-	/go/src/app/pkg/resourcetree/resourcetree.go:95 +0x188 fp=0xc00011fb00 sp=0xc00011fa50 pc=0xb5baa8
-sigs.k8s.io/k8s-api-coverage/pkg/webhook.(*APICoverageRecorder).GetResourceCoveragePercentages(0xc0003cbe00, 0x14c1400, 0xc0016a6050, 0xc000f0c500)
-	/go/src/app/pkg/webhook/apicoverage_recorder.go:247 +0x33a fp=0xc00011fcb8 sp=0xc00011fb00 pc=0x109f9aa
-sigs.k8s.io/k8s-api-coverage/pkg/webhook.(*APICoverageRecorder).GetResourceCoveragePercentages-fm(0x14c1400, 0xc0016a6050, 0xc000f0c500)
-	/go/src/app/pkg/webhook/apicoverage_recorder.go:228 +0x48 fp=0xc00011fce8 sp=0xc00011fcb8 pc=0x10a3238
-
-goroutine 6 [runnable]:
-strconv.frexp10Many(0xc001a39858, 0xc001a398a0, 0xc001a39840, 0x1929e)
-	/usr/local/go/src/strconv/extfloat.go:349 +0xea
-strconv.(*extFloat).ShortestDecimal(0xc001a398a0, 0xc001a398f0, 0xc001a39858, 0xc001a39840, 0x1e91090)
-	/usr/local/go/src/strconv/extfloat.go:556 +0x21b
-...
-go.uber.org/zap.(*SugaredLogger).Info(...)
-	/go/pkg/mod/go.uber.org/zap@v1.12.0/sugar.go:102
-sigs.k8s.io/k8s-api-coverage/pkg/webhook.(*APICoverageRecorder).updateResourceCoverageTree(0xc0003cbe00)
-	/go/src/app/pkg/webhook/apicoverage_recorder.go:100 +0x550
-created by sigs.k8s.io/k8s-api-coverage/pkg/webhook.(*APICoverageRecorder).Init
-	/go/src/app/pkg/webhook/apicoverage_recorder.go:83 +0x311
+// NOTE: by default the total number of fields to cover grows as the cluster is
+// exercised, as the types of nil pointers aren't outlined until they're set.
+// Pass -all-types to count the fields of every reachable type from the start.
 
-*/
+// TODO(spiffxp): Admission webhooks don't get access to user agent, so coverage
+// recorded through them is only attributed to a test via the username, or the
+// webhook.TestNameAnnotation on the object. Audit events do carry the user
+// agent, and are accepted at webhook.AuditEventsEndPoint, but dynamic audit is
+// still alpha so not configurable by default
 
 // main builds the necessary webhook configuration, HTTPServer and starts the webhook.
 func main() {
+	flag.Parse()
 	namespace := common.WebhookNamespace
 	if len(namespace) == 0 {
 		log.Fatal("Namespace value to used by the webhook is not set")
 	}
 
 	webhookConf := webhook.BuildWebhookConfiguration(common.CommonComponentName, common.WebhookNamespace)
+	filter := common.ResourceFilter{
+		IncludeGroups:   flagSet(*includeGroupsFlag),
+		ExcludeGroups:   flagSet(*excludeGroupsFlag),
+		IncludeVersions: flagSet(*includeVersionsFlag),
+		ExcludeVersions: flagSet(*excludeVersionsFlag),
+		IncludeKinds:    flagSet(*includeKindsFlag),
+		ExcludeKinds:    flagSet(*excludeKindsFlag),
+	}
+	resourceMap := common.FilterResourceMap(common.ResourceMap, filter)
+	var schemaMap map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps
+	if *discoveryFlag {
+		crdClient, err := kube.BuildAPIExtensionsClient()
+		if err != nil {
+			log.Fatalf("Failed to get apiextensions client set: %v", err)
+		}
+		resourceMap, schemaMap, err = common.DiscoverResources(webhookConf.KubeClient.Discovery(), crdClient, filter)
+		if err != nil {
+			log.Fatalf("Failed to discover resources: %v", err)
+		}
+		log.Printf("Discovered %d built-in and %d custom resources", len(resourceMap), len(schemaMap))
+	}
 	recorder := webhook.APICoverageRecorder{
 		Logger: webhookConf.Logger,
 		ResourceForest: resourcetree.ResourceForest{
-			Version:        "v1alpha1",
-			ConnectedNodes: make(map[string]*list.List),
-			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+			Version:         "v1alpha1",
+			ConnectedNodes:  make(map[string]*list.List),
+			TopLevelTrees:   make(map[string]resourcetree.ResourceTree),
+			ExpectedMapKeys: rules.ExpectedMapKeys,
 		},
-		ResourceMap:  common.ResourceMap,
-		NodeRules:    rules.NodeRules,
-		FieldRules:   rules.FieldRules,
-		DisplayRules: rules.GetDisplayRules(),
+		ResourceMap:    resourceMap,
+		SchemaMap:      schemaMap,
+		SubresourceMap: common.FilterSubresourceMap(common.SubresourceMap, filter),
+		OptionsMap:     common.FilterResourceMap(common.OptionsMap, filter),
+		NodeRules:      rules.NodeRules,
+		FieldRules:     rules.FieldRules,
+		DisplayRules:   rules.GetDisplayRules(),
+		AllTypes:       *allTypesFlag,
+	}
+	if *checkpointStoreFlag != "" {
+		store, err := checkpoint.NewStore(*checkpointStoreFlag, webhookConf.KubeClient, namespace)
+		if err != nil {
+			log.Fatalf("Failed to build checkpoint store: %v", err)
+		}
+		recorder.CheckpointStore = store
+		recorder.CheckpointInterval = *checkpointIntervalFlag
 	}
 	recorder.Init()
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", recorder.RecordResourceCoverage)
+	mux.HandleFunc(webhook.AuditEventsEndPoint, recorder.RecordAuditEvents)
 	mux.HandleFunc(webhook.ResourceCoverageEndPoint, recorder.GetResourceCoverage)
+	mux.HandleFunc(webhook.ResourcePathCoverageEndPoint, recorder.GetResourcePathCoverage)
 	mux.HandleFunc(webhook.TotalCoverageEndPoint, recorder.GetTotalCoverage)
 	mux.HandleFunc(webhook.ResourcePercentageCoverageEndPoint, recorder.GetResourceCoveragePercentages)
+	mux.HandleFunc(webhook.TestCoverageEndPoint, recorder.GetTestCoverage)
 
 	// TODO(spiffxp): expose on its own mux like prow does?
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -129,13 +136,39 @@ func main() {
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
 
+	// Rules for resources include their subresources and options, so
+	// SubresourceMap and OptionsMap need no rules of their own
 	resources := []schema.GroupVersionKind{}
 	for gvk := range recorder.ResourceMap {
 		resources = append(resources, gvk)
 	}
+	for gvk := range recorder.SchemaMap {
+		resources = append(resources, gvk)
+	}
 	log.Printf("Passing in resources %+v", resources)
-	err := webhookConf.Run(mux, resources, namespace, signals.SetupSignalHandler())
+	stop := signals.SetupSignalHandler()
+	if recorder.CheckpointStore != nil {
+		go recorder.RunCheckpoints(stop)
+	}
+	err := webhookConf.Run(mux, resources, namespace, stop)
+	// Run returns once stop is closed (eg: on SIGTERM), record the resources
+	// still queued, and save whatever was recorded since the last checkpoint
+	// before exiting
+	recorder.Stop()
+	if recorder.CheckpointStore != nil {
+		if err := recorder.Checkpoint(); err != nil {
+			log.Printf("Failed saving coverage checkpoint: %v", err)
+		}
+	}
 	if err != nil {
 		log.Fatalf("Encountered error setting up Webhook: %v", err)
 	}
 }
+
+// flagSet returns the set of values in a comma separated flag.
+func flagSet(value string) sets.String {
+	if value == "" {
+		return sets.String{}
+	}
+	return sets.NewString(strings.Split(value, ",")...)
+}
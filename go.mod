@@ -12,9 +12,9 @@ replace contrib.go.opencensus.io/exporter/stackdriver => contrib.go.opencensus.i
 //   go get k8s.io/$repo@$sha
 // done
 replace (
-	k8s.io/api => k8s.io/api v0.0.0-20190819141258-3544db3b9e44
-	k8s.io/apimachinery => k8s.io/apimachinery v0.0.0-20190817020851-f2f3a405f61d
-	k8s.io/client-go => k8s.io/client-go v0.0.0-20190819141724-e14f31a72a77
+	k8s.io/api => k8s.io/api v0.0.0-20191016110408-35e52d86657a
+	k8s.io/apimachinery => k8s.io/apimachinery v0.0.0-20191004115801-a2eda9f80ab8
+	k8s.io/client-go => k8s.io/client-go v0.0.0-20191016111102-bec269661e48
 )
 
 // [[override]]
@@ -76,7 +76,6 @@ require (
 	contrib.go.opencensus.io/exporter/prometheus v0.1.0 // indirect
 	contrib.go.opencensus.io/exporter/stackdriver v0.0.0-00010101000000-000000000000 // indirect
 	github.com/google/go-containerregistry v0.0.0-20191029173801-50b26ee28691 // indirect
-	github.com/markbates/inflect v1.0.4
 	github.com/mattbaird/jsonpatch v0.0.0-20171005235357-81af80346b1a // indirect
 	github.com/pkg/errors v0.8.1
 	go.opencensus.io v0.22.1 // indirect
@@ -85,9 +84,12 @@ require (
 	google.golang.org/genproto v0.0.0-20191028173616-919d9bdd9fe6 // indirect
 	gopkg.in/yaml.v2 v2.2.4
 	k8s.io/api v0.0.0-20191016110408-35e52d86657a
+	k8s.io/apiextensions-apiserver v0.0.0-20191016113550-5357c4baaf65
 	k8s.io/apimachinery v0.0.0-20191004115801-a2eda9f80ab8
+	k8s.io/apiserver v0.0.0-20191016112112-5190913f932d
 	k8s.io/client-go v0.0.0-20191016111102-bec269661e48
 	knative.dev/pkg v0.0.0-20191030060811-3732de580201
 	knative.dev/serving v0.10.0
 	knative.dev/test-infra v0.0.0-20191030013311-34a629e61afc
+	sigs.k8s.io/yaml v1.1.0
 )
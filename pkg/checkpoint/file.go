@@ -0,0 +1,64 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStore saves checkpoints to a local file, e.g. on a volume that outlives
+// the webhook's container.
+type FileStore struct {
+	Path string
+}
+
+var _ Store = &FileStore{}
+
+// Load reads the checkpoint file, if it exists.
+func (f *FileStore) Load() ([]byte, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file %s: %v", f.Path, err)
+	}
+	return data, nil
+}
+
+// Save writes the checkpoint file. The file is replaced via rename so a
+// crash while saving leaves the previous checkpoint intact.
+func (f *FileStore) Save(data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(f.Path), filepath.Base(f.Path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("error creating checkpoint file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing checkpoint file %s: %v", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing checkpoint file %s: %v", tmp.Name(), err)
+	}
+	if err := os.Rename(tmp.Name(), f.Path); err != nil {
+		return fmt.Errorf("error replacing checkpoint file %s: %v", f.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// checkpointKey is the key under which checkpoints are stored in
+	// ConfigMaps and Secrets. Checkpoints are gzipped to stay well under
+	// the 1MiB size limit of both.
+	checkpointKey = "coverage.json.gz"
+)
+
+// ConfigMapStore saves checkpoints to a ConfigMap, which is created if it
+// doesn't exist.
+type ConfigMapStore struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	Name       string
+}
+
+var _ Store = &ConfigMapStore{}
+
+// Load reads the checkpoint from the ConfigMap, if it exists.
+func (c *ConfigMapStore) Load() ([]byte, error) {
+	cm, err := c.KubeClient.CoreV1().ConfigMaps(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving checkpoint ConfigMap %s/%s: %v", c.Namespace, c.Name, err)
+	}
+	if _, ok := cm.BinaryData[checkpointKey]; !ok {
+		return nil, nil
+	}
+	return decompress(cm.BinaryData[checkpointKey])
+}
+
+// Save writes the checkpoint to the ConfigMap.
+func (c *ConfigMapStore) Save(data []byte) error {
+	compressed, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("error compressing checkpoint: %v", err)
+	}
+
+	client := c.KubeClient.CoreV1().ConfigMaps(c.Namespace)
+	cm, err := client.Get(c.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.Name,
+				Namespace: c.Namespace,
+			},
+			BinaryData: map[string][]byte{checkpointKey: compressed},
+		}
+		if _, err := client.Create(cm); err != nil {
+			return fmt.Errorf("error creating checkpoint ConfigMap %s/%s: %v", c.Namespace, c.Name, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error retrieving checkpoint ConfigMap %s/%s: %v", c.Namespace, c.Name, err)
+	}
+
+	if cm.BinaryData == nil {
+		cm.BinaryData = map[string][]byte{}
+	}
+	cm.BinaryData[checkpointKey] = compressed
+	if _, err := client.Update(cm); err != nil {
+		return fmt.Errorf("error updating checkpoint ConfigMap %s/%s: %v", c.Namespace, c.Name, err)
+	}
+	return nil
+}
+
+// SecretStore saves checkpoints to a Secret, which is created if it doesn't
+// exist.
+type SecretStore struct {
+	KubeClient kubernetes.Interface
+	Namespace  string
+	Name       string
+}
+
+var _ Store = &SecretStore{}
+
+// Load reads the checkpoint from the Secret, if it exists.
+func (s *SecretStore) Load() ([]byte, error) {
+	secret, err := s.KubeClient.CoreV1().Secrets(s.Namespace).Get(s.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving checkpoint Secret %s/%s: %v", s.Namespace, s.Name, err)
+	}
+	if _, ok := secret.Data[checkpointKey]; !ok {
+		return nil, nil
+	}
+	return decompress(secret.Data[checkpointKey])
+}
+
+// Save writes the checkpoint to the Secret.
+func (s *SecretStore) Save(data []byte) error {
+	compressed, err := compress(data)
+	if err != nil {
+		return fmt.Errorf("error compressing checkpoint: %v", err)
+	}
+
+	client := s.KubeClient.CoreV1().Secrets(s.Namespace)
+	secret, err := client.Get(s.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.Name,
+				Namespace: s.Namespace,
+			},
+			Data: map[string][]byte{checkpointKey: compressed},
+		}
+		if _, err := client.Create(secret); err != nil {
+			return fmt.Errorf("error creating checkpoint Secret %s/%s: %v", s.Namespace, s.Name, err)
+		}
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error retrieving checkpoint Secret %s/%s: %v", s.Namespace, s.Name, err)
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[checkpointKey] = compressed
+	if _, err := client.Update(secret); err != nil {
+		return fmt.Errorf("error updating checkpoint Secret %s/%s: %v", s.Namespace, s.Name, err)
+	}
+	return nil
+}
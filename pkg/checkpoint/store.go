@@ -0,0 +1,79 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package checkpoint contains backends used to persist coverage state, so it
+// survives restarts of the webhook
+package checkpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// Store saves and loads checkpoints of coverage state.
+type Store interface {
+	// Load returns the last saved checkpoint, or nil if none was saved.
+	Load() ([]byte, error)
+	// Save replaces the last saved checkpoint.
+	Save(data []byte) error
+}
+
+// NewStore returns the Store described by spec, which is one of:
+// - file:<path> for a FileStore
+// - configmap:<name> for a ConfigMapStore in the given namespace
+// - secret:<name> for a SecretStore in the given namespace
+func NewStore(spec string, kubeClient kubernetes.Interface, namespace string) (Store, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) != 2 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("invalid checkpoint store %q, expected <kind>:<name>", spec)
+	}
+	switch parts[0] {
+	case "file":
+		return &FileStore{Path: parts[1]}, nil
+	case "configmap":
+		return &ConfigMapStore{KubeClient: kubeClient, Namespace: namespace, Name: parts[1]}, nil
+	case "secret":
+		return &SecretStore{KubeClient: kubeClient, Namespace: namespace, Name: parts[1]}, nil
+	}
+	return nil, fmt.Errorf("invalid checkpoint store %q, unknown kind %q", spec, parts[0])
+}
+
+// compress is used by backends with a size limit, like ConfigMaps and Secrets
+func compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
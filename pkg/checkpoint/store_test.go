@@ -0,0 +1,55 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package checkpoint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, spec := range []string{"file:" + filepath.Join(dir, "coverage.json"), "configmap:coverage", "secret:coverage"} {
+		t.Run(spec, func(t *testing.T) {
+			store, err := NewStore(spec, fake.NewSimpleClientset(), "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if data, err := store.Load(); err != nil || data != nil {
+				t.Fatalf("Expected no checkpoint before the first save, found: %q, error: %v", data, err)
+			}
+			for _, expected := range []string{"first", "second"} {
+				if err := store.Save([]byte(expected)); err != nil {
+					t.Fatal(err)
+				}
+				if data, err := store.Load(); err != nil || string(data) != expected {
+					t.Fatalf("Expected checkpoint %q, found: %q, error: %v", expected, data, err)
+				}
+			}
+		})
+	}
+}
@@ -24,19 +24,50 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	authenticationv1 "k8s.io/api/authentication/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/k8s-api-coverage/pkg/webhook"
 )
 
 var (
 	// ResourceMap is a hardcoded map of GVK to reflect.Type
 	ResourceMap = buildResourceMap()
+
+	// SubresourceMap is a hardcoded map of subresource to the reflect.Type of
+	// its request bodies, for the subresources of ResourceMap whose bodies are
+	// not the resource itself. Not included is pods/log, as its PodLogOptions
+	// are query params of a GET, which neither admission webhooks nor audit
+	// events are sent a body for.
+	SubresourceMap = map[webhook.Subresource]reflect.Type{
+		{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "attach"}:                  reflect.TypeOf(corev1.PodAttachOptions{}),
+		{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "binding"}:                 reflect.TypeOf(corev1.Binding{}),
+		{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "eviction"}:                reflect.TypeOf(policyv1beta1.Eviction{}),
+		{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "exec"}:                    reflect.TypeOf(corev1.PodExecOptions{}),
+		{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "portforward"}:             reflect.TypeOf(corev1.PodPortForwardOptions{}),
+		{Resource: corev1.SchemeGroupVersion.WithResource("replicationcontrollers"), Subresource: "scale"}: reflect.TypeOf(autoscalingv1.Scale{}),
+		{Resource: appsv1.SchemeGroupVersion.WithResource("deployments"), Subresource: "scale"}:            reflect.TypeOf(autoscalingv1.Scale{}),
+		{Resource: appsv1.SchemeGroupVersion.WithResource("replicasets"), Subresource: "scale"}:            reflect.TypeOf(autoscalingv1.Scale{}),
+		{Resource: appsv1.SchemeGroupVersion.WithResource("statefulsets"), Subresource: "scale"}:           reflect.TypeOf(autoscalingv1.Scale{}),
+	}
+
+	// OptionsMap is a hardcoded map of GVK to reflect.Type for the options
+	// that admission webhooks are sent along with CREATE, UPDATE and DELETE
+	// requests, eg: to cover DeleteOptions.PropagationPolicy
+	OptionsMap = map[schema.GroupVersionKind]reflect.Type{
+		metav1.SchemeGroupVersion.WithKind("CreateOptions"): reflect.TypeOf(metav1.CreateOptions{}),
+		metav1.SchemeGroupVersion.WithKind("UpdateOptions"): reflect.TypeOf(metav1.UpdateOptions{}),
+		metav1.SchemeGroupVersion.WithKind("DeleteOptions"): reflect.TypeOf(metav1.DeleteOptions{}),
+	}
 )
 
 // buildResourceMap returns a map of GVK to reflect.Type for all kubernetes v1
@@ -45,6 +76,7 @@ func buildResourceMap() map[schema.GroupVersionKind]reflect.Type {
 	gvkToType := make(map[schema.GroupVersionKind]reflect.Type)
 	// Should I be making my own schemes or is there some place I can find pre-built schemes?
 	schemeBuilders := []runtime.SchemeBuilder{
+		apiextensionsv1.SchemeBuilder,
 		appsv1.SchemeBuilder,
 		authenticationv1.SchemeBuilder,
 		batchv1.SchemeBuilder,
@@ -69,6 +101,13 @@ func buildResourceMap() map[schema.GroupVersionKind]reflect.Type {
 	// are not allowed to rely on it, it's nothing but optional fields with no
 	// guarantee of delivery
 	delete(gvkToType, corev1.SchemeGroupVersion.WithKind("Event"))
+	// Options are registered in every group version, but they're the same
+	// types everywhere, so they're recorded once as OptionsMap instead
+	for gvk := range gvkToType {
+		if _, ok := OptionsMap[metav1.SchemeGroupVersion.WithKind(gvk.Kind)]; ok {
+			delete(gvkToType, gvk)
+		}
+	}
 	return gvkToType
 }
 
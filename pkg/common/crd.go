@@ -0,0 +1,120 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsinstall "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/install"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// crd.go contains helpers to measure coverage of CustomResourceDefinitions,
+// whose resources have no Go types, from their OpenAPI v3 schemas.
+
+var (
+	crdScheme = buildCRDScheme()
+)
+
+func buildCRDScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	apiextensionsinstall.Install(s)
+	return s
+}
+
+// SchemaMapForCRD returns a map of GVK to OpenAPI v3 schema for every served
+// version of a CustomResourceDefinition that has a schema.
+func SchemaMapForCRD(crd *apiextensionsv1.CustomResourceDefinition) map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps {
+	gvkToSchema := make(map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps)
+	for _, version := range crd.Spec.Versions {
+		if !version.Served || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		gvk := schema.GroupVersionKind{
+			Group:   crd.Spec.Group,
+			Version: version.Name,
+			Kind:    crd.Spec.Names.Kind,
+		}
+		gvkToSchema[gvk] = version.Schema.OpenAPIV3Schema
+	}
+	return gvkToSchema
+}
+
+// ReadCRDs reads CustomResourceDefinitions from a stream of YAML or JSON
+// documents, eg: a manifest file. Both apiextensions.k8s.io/v1 and v1beta1
+// CustomResourceDefinitions are returned as v1, other kinds are skipped.
+func ReadCRDs(r io.Reader) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	// Decoded into the internal version, see toV1CRD
+	decoder := serializer.NewCodecFactory(crdScheme).UniversalDecoder()
+	reader := yaml.NewYAMLReader(bufio.NewReader(r))
+	crds := []*apiextensionsv1.CustomResourceDefinition{}
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return crds, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to read document: %v", err)
+		}
+
+		typeMeta := runtime.TypeMeta{}
+		if err := sigsyaml.Unmarshal(document, &typeMeta); err != nil {
+			return nil, fmt.Errorf("unable to decode document type: %v", err)
+		}
+		if typeMeta.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		obj, _, err := decoder.Decode(document, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode CustomResourceDefinition: %v", err)
+		}
+		crd, err := toV1CRD(obj)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, crd)
+	}
+}
+
+// toV1CRD converts an internal, v1beta1 or v1 CustomResourceDefinition to v1.
+// CustomResourceDefinitions can only be converted between versions through
+// the internal version.
+func toV1CRD(obj runtime.Object) (*apiextensionsv1.CustomResourceDefinition, error) {
+	if crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition); ok {
+		return crd, nil
+	}
+	if _, ok := obj.(*apiextensionsv1beta1.CustomResourceDefinition); ok {
+		internal := &apiextensions.CustomResourceDefinition{}
+		if err := crdScheme.Convert(obj, internal, nil); err != nil {
+			return nil, fmt.Errorf("unable to convert CustomResourceDefinition: %v", err)
+		}
+		obj = internal
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := crdScheme.Convert(obj, crd, nil); err != nil {
+		return nil, fmt.Errorf("unable to convert CustomResourceDefinition: %v", err)
+	}
+	return crd, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const testCRDs = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: foo
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  names:
+    kind: Foo
+    plural: foos
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            replicas:
+              type: integer
+            mode:
+              type: string
+              enum: ["Fast", "Slow"]
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: bars.example.com
+spec:
+  group: example.com
+  names:
+    kind: Bar
+    plural: bars
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: string`
+
+func TestReadCRDs(t *testing.T) {
+	crds, err := ReadCRDs(strings.NewReader(testCRDs))
+	if err != nil {
+		t.Fatalf("Error reading CustomResourceDefinitions: %v", err)
+	}
+	if len(crds) != 2 {
+		t.Fatalf("Expected 2 CustomResourceDefinitions, found: %d", len(crds))
+	}
+
+	for gvk, properties := range map[schema.GroupVersionKind][]string{
+		{Group: "example.com", Version: "v1", Kind: "Foo"}:       {"replicas", "mode"},
+		{Group: "example.com", Version: "v1alpha1", Kind: "Bar"}: {"size"},
+	} {
+		found := false
+		for _, crd := range crds {
+			if crdSchema, ok := SchemaMapForCRD(crd)[gvk]; ok {
+				found = true
+				for _, property := range properties {
+					if _, ok := crdSchema.Properties["spec"].Properties[property]; !ok {
+						t.Errorf("Expected property spec.%s in the schema of %v", property, gvk)
+					}
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected a schema for %v", gvk)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/k8s-api-coverage/pkg/webhook"
+)
+
+// discovery.go contains helpers to setup coverage of the resources an API
+// server actually serves, instead of the hardcoded ResourceMap.
+
+const (
+	// CoreGroupName is how ResourceFilter refers to the legacy "" group.
+	CoreGroupName = "core"
+)
+
+// ResourceFilter selects resources by group, version and kind. Empty include
+// sets select everything, and excludes take precedence over includes.
+type ResourceFilter struct {
+	IncludeGroups   sets.String
+	ExcludeGroups   sets.String
+	IncludeVersions sets.String
+	ExcludeVersions sets.String
+	IncludeKinds    sets.String
+	ExcludeKinds    sets.String
+}
+
+// Matches returns whether the filter selects a given GVK.
+func (f ResourceFilter) Matches(gvk schema.GroupVersionKind) bool {
+	group := gvk.Group
+	if group == "" {
+		group = CoreGroupName
+	}
+	return filterMatches(f.IncludeGroups, f.ExcludeGroups, group) &&
+		filterMatches(f.IncludeVersions, f.ExcludeVersions, gvk.Version) &&
+		filterMatches(f.IncludeKinds, f.ExcludeKinds, gvk.Kind)
+}
+
+func filterMatches(include sets.String, exclude sets.String, value string) bool {
+	return (include.Len() == 0 || include.Has(value)) && !exclude.Has(value)
+}
+
+// FilterResourceMap returns the entries of a map of GVK to reflect.Type that
+// the filter selects.
+func FilterResourceMap(resourceMap map[schema.GroupVersionKind]reflect.Type, filter ResourceFilter) map[schema.GroupVersionKind]reflect.Type {
+	gvkToType := make(map[schema.GroupVersionKind]reflect.Type)
+	for gvk, resourceType := range resourceMap {
+		if filter.Matches(gvk) {
+			gvkToType[gvk] = resourceType
+		}
+	}
+	return gvkToType
+}
+
+// FilterSubresourceMap returns the entries of a map of subresource to
+// reflect.Type that the filter selects, by the group and version of their
+// resource and the kind of their request bodies.
+func FilterSubresourceMap(subresourceMap map[webhook.Subresource]reflect.Type, filter ResourceFilter) map[webhook.Subresource]reflect.Type {
+	subresourceToType := make(map[webhook.Subresource]reflect.Type)
+	for subresource, bodyType := range subresourceMap {
+		if filter.Matches(subresource.Resource.GroupVersion().WithKind(bodyType.Name())) {
+			subresourceToType[subresource] = bodyType
+		}
+	}
+	return subresourceToType
+}
+
+// DiscoverResources asks the API server which resources it serves, and
+// returns those selected by the filter as a map of GVK to reflect.Type for the
+// built-in types client-go knows about, and a map of GVK to OpenAPI v3 schema
+// for custom resources. Subresources, and resources that are neither, eg:
+// those of aggregated API servers, are skipped. CustomResourceDefinitions are
+// not listed if crdClient is nil.
+func DiscoverResources(discoveryClient discovery.DiscoveryInterface, crdClient apiextensionsclientset.Interface, filter ResourceFilter) (map[schema.GroupVersionKind]reflect.Type, map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps, error) {
+	_, resourceLists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		// Some aggregated API servers may be unavailable, carry on with the
+		// groups that were discovered
+		if !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, nil, fmt.Errorf("unable to discover served resources: %v", err)
+		}
+		log.Printf("Skipping groups that failed discovery: %v", err)
+	}
+
+	crdSchemaMap := make(map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps)
+	if crdClient != nil {
+		crds, err := listCRDs(crdClient)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, crd := range crds {
+			for gvk, crdSchema := range SchemaMapForCRD(crd) {
+				crdSchemaMap[gvk] = crdSchema
+			}
+		}
+	}
+
+	knownTypes := scheme.Scheme.AllKnownTypes()
+	gvkToType := make(map[schema.GroupVersionKind]reflect.Type)
+	gvkToSchema := make(map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps)
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to parse discovered group version: %v", err)
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			gvk := gv.WithKind(resource.Kind)
+			if !filter.Matches(gvk) {
+				continue
+			}
+			if resourceType, ok := knownTypes[gvk]; ok {
+				gvkToType[gvk] = resourceType
+			} else if crdSchema, ok := crdSchemaMap[gvk]; ok {
+				gvkToSchema[gvk] = crdSchema
+			} else {
+				log.Printf("Skipping resource %s of %s: no known type or schema", resource.Name, gv)
+			}
+		}
+	}
+	return gvkToType, gvkToSchema, nil
+}
+
+// listCRDs lists CustomResourceDefinitions as v1, through v1beta1 if the API
+// server is older than kubernetes 1.16.
+func listCRDs(crdClient apiextensionsclientset.Interface) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	crds := []*apiextensionsv1.CustomResourceDefinition{}
+	crdList, err := crdClient.ApiextensionsV1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err == nil {
+		for i := range crdList.Items {
+			crds = append(crds, &crdList.Items[i])
+		}
+		return crds, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to list CustomResourceDefinitions: %v", err)
+	}
+
+	v1beta1CRDList, err := crdClient.ApiextensionsV1beta1().CustomResourceDefinitions().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list CustomResourceDefinitions: %v", err)
+	}
+	for i := range v1beta1CRDList.Items {
+		crd, err := toV1CRD(&v1beta1CRDList.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common
+
+import (
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsfake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func getTestDiscovery() *fakediscovery.FakeDiscovery {
+	discoveryClient := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+	discoveryClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod"},
+			{Name: "pods/log", Kind: "Pod"},
+			{Name: "events", Kind: "Event"},
+		},
+	}, {
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment"},
+		},
+	}, {
+		GroupVersion: "example.com/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "foos", Kind: "Foo"},
+		},
+	}, {
+		GroupVersion: "metrics.k8s.io/v1beta1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "PodMetrics"},
+		},
+	}}
+	return discoveryClient
+}
+
+func getTestCRD() *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "foos.example.com"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "example.com",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Kind: "Foo", Plural: "foos"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:   "v1",
+				Served: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{
+					OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{Type: "object"},
+				},
+			}},
+		},
+	}
+}
+
+func TestDiscoverResources(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	deploymentGVK := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+	fooGVK := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+
+	tests := []struct {
+		name            string
+		filter          ResourceFilter
+		expectedTypes   []schema.GroupVersionKind
+		expectedSchemas []schema.GroupVersionKind
+	}{{
+		name:            "exclude events",
+		filter:          ResourceFilter{ExcludeKinds: sets.NewString("Event")},
+		expectedTypes:   []schema.GroupVersionKind{podGVK, deploymentGVK},
+		expectedSchemas: []schema.GroupVersionKind{fooGVK},
+	}, {
+		name:          "include core group",
+		filter:        ResourceFilter{IncludeGroups: sets.NewString(CoreGroupName), ExcludeKinds: sets.NewString("Event")},
+		expectedTypes: []schema.GroupVersionKind{podGVK},
+	}, {
+		name:            "exclude apps group",
+		filter:          ResourceFilter{ExcludeGroups: sets.NewString("apps", CoreGroupName)},
+		expectedSchemas: []schema.GroupVersionKind{fooGVK},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gvkToType, gvkToSchema, err := DiscoverResources(getTestDiscovery(), apiextensionsfake.NewSimpleClientset(getTestCRD()), test.filter)
+			if err != nil {
+				t.Fatalf("Error discovering resources: %v", err)
+			}
+			if len(gvkToType) != len(test.expectedTypes) {
+				t.Errorf("Expected types for %v, found: %v", test.expectedTypes, gvkToType)
+			}
+			for _, gvk := range test.expectedTypes {
+				if _, ok := gvkToType[gvk]; !ok {
+					t.Errorf("Expected a type for %v, found: %v", gvk, gvkToType)
+				}
+			}
+			if len(gvkToSchema) != len(test.expectedSchemas) {
+				t.Errorf("Expected schemas for %v, found: %v", test.expectedSchemas, gvkToSchema)
+			}
+			for _, gvk := range test.expectedSchemas {
+				if _, ok := gvkToSchema[gvk]; !ok {
+					t.Errorf("Expected a schema for %v, found: %v", gvk, gvkToSchema)
+				}
+			}
+		})
+	}
+}
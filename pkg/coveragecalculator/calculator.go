@@ -25,6 +25,15 @@ type CoverageValues struct {
 	TotalFields   int
 	CoveredFields int
 	IgnoredFields int
+	// MutatedFields is the number of fields whose value was changed by an
+	// update, see FieldCoverage.Mutated
+	MutatedFields int
+	// PresentFields is the number of fields explicitly set by a request, see
+	// FieldCoverage.Present
+	PresentFields int
+	// PartialFields is the number of covered fields that haven't taken all the
+	// values they're expected to, see FieldCoverage.Partial
+	PartialFields int
 
 	PercentCoverage float64
 }
@@ -34,6 +43,14 @@ type CoveragePercentages struct {
 
 	// ResourceCoverages maps percentage coverage per resource.
 	ResourceCoverages map[string]float64
+
+	// SubresourceCoverages maps percentage coverage per subresource, eg: pods/exec,
+	// for subresources whose request bodies are not the resource itself.
+	SubresourceCoverages map[string]float64 `json:",omitempty"`
+
+	// OptionsCoverages maps percentage coverage per options sent along with
+	// requests, eg: DeleteOptions.
+	OptionsCoverages map[string]float64 `json:",omitempty"`
 }
 
 // CalculatePercentageValue calculates percentage value based on other fields.
@@ -48,6 +65,9 @@ func (c *CoverageValues) Accumulate(c2 CoverageValues) {
 	c.TotalFields += c2.TotalFields
 	c.CoveredFields += c2.CoveredFields
 	c.IgnoredFields += c2.IgnoredFields
+	c.MutatedFields += c2.MutatedFields
+	c.PresentFields += c2.PresentFields
+	c.PartialFields += c2.PartialFields
 	c.CalculatePercentageValue()
 }
 
@@ -79,6 +99,15 @@ func CalculateTypeCoverage(typeCoverage []TypeCoverage) CoverageValues {
 			} else if field.Coverage {
 				cv.CoveredFields++
 			}
+			if !field.Ignored && field.Mutated {
+				cv.MutatedFields++
+			}
+			if !field.Ignored && field.Present {
+				cv.PresentFields++
+			}
+			if !field.Ignored && field.Partial() {
+				cv.PartialFields++
+			}
 		}
 	}
 	cv.CalculatePercentageValue()
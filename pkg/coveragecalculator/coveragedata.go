@@ -17,27 +17,116 @@ limitations under the License.
 package coveragecalculator
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // FieldCoverage represents coverage data for a field.
 type FieldCoverage struct {
-	Field    string      `json:"Field"`
-	Values   sets.String `json:"Values"`
-	Coverage bool        `json:"Covered"`
-	Ignored  bool        `json:"Ignored"`
+	Field      string      `json:"Field"`
+	Values     sets.String `json:"Values"`
+	Coverage   bool        `json:"Covered"`
+	Ignored    bool        `json:"Ignored"`
+	Tests      sets.String `json:"Tests"`
+	Operations sets.String `json:"Operations"`
+	// Mutated is whether an update changed the field's value, as opposed to
+	// sending it back unchanged.
+	Mutated bool `json:"Mutated"`
+	// Present is whether a request explicitly set the field, even to its zero
+	// value, eg: false or "", which aren't counted as Coverage.
+	Present bool `json:"Present"`
+	// Expected is the values the field is expected to take, eg: those of an
+	// enum, or the well-known keys of a map. Fields covered without all of
+	// them are Partial.
+	Expected sets.String `json:"Expected,omitempty"`
+	// Hits is the number of times the field was covered, eg: to tell fields
+	// covered by a single request from those every test covers.
+	Hits int `json:"Hits"`
+	// ValueHits is the number of times the field took each of Values, if counted.
+	ValueHits map[string]int `json:"ValueHits,omitempty"`
+	// FirstSeen and LastSeen are the times of the earliest and latest
+	// requests that covered the field.
+	FirstSeen time.Time `json:"FirstSeen"`
+	LastSeen  time.Time `json:"LastSeen"`
+	// Exemplars are up to MaxExemplars of the objects that covered the field.
+	Exemplars []Exemplar `json:"Exemplars,omitempty"`
 }
 
 // Merge operation merges the field coverage data when multiple nodes represent the same type. (e.g. ConnectedNodes traversal)
-func (f *FieldCoverage) Merge(coverage bool, values sets.String) {
+func (f *FieldCoverage) Merge(coverage bool, values sets.String, tests sets.String, operations sets.String) {
 	if coverage {
 		f.Coverage = coverage
 		f.Values = f.Values.Union(values)
+		f.Tests = f.Tests.Union(tests)
+		f.Operations = f.Operations.Union(operations)
 	}
 }
 
+// MergeMutated merges whether the field was mutated, when multiple nodes represent the same type.
+func (f *FieldCoverage) MergeMutated(mutated bool) {
+	f.Mutated = f.Mutated || mutated
+}
+
+// MergePresent merges whether the field was present, when multiple nodes represent the same type.
+func (f *FieldCoverage) MergePresent(present bool) {
+	f.Present = f.Present || present
+}
+
+// MergeExpected merges the values the field is expected to take, when multiple nodes represent the same type.
+func (f *FieldCoverage) MergeExpected(expected sets.String) {
+	if len(expected) != 0 {
+		f.Expected = f.Expected.Union(expected)
+	}
+}
+
+// MergeHits merges the number of times the field was covered, and when, when multiple nodes represent the same type.
+func (f *FieldCoverage) MergeHits(coverage bool, hits int, firstSeen time.Time, lastSeen time.Time, valueHits map[string]int) {
+	if !coverage {
+		return
+	}
+	f.Hits += hits
+	if f.FirstSeen.IsZero() || firstSeen.Before(f.FirstSeen) {
+		f.FirstSeen = firstSeen
+	}
+	if lastSeen.After(f.LastSeen) {
+		f.LastSeen = lastSeen
+	}
+	if len(valueHits) != 0 && f.ValueHits == nil {
+		f.ValueHits = map[string]int{}
+	}
+	for value, valueHits := range valueHits {
+		f.ValueHits[value] += valueHits
+	}
+}
+
+// MergeExemplars merges the objects that covered the field, when multiple nodes represent the same type.
+func (f *FieldCoverage) MergeExemplars(coverage bool, exemplars []Exemplar) {
+	if !coverage {
+		return
+	}
+	for _, exemplar := range exemplars {
+		f.Exemplars = AddExemplar(f.Exemplars, exemplar)
+	}
+}
+
+// Partial returns whether the field is covered, but hasn't taken all the values it's expected to.
+func (f *FieldCoverage) Partial() bool {
+	return f.Coverage && len(f.Expected) != 0 && !f.Values.IsSuperset(f.Expected)
+}
+
+// GetExpectedSeen returns the number of expected values the field has taken.
+func (f *FieldCoverage) GetExpectedSeen() int {
+	return f.Expected.Intersection(f.Values).Len()
+}
+
+// GetMissingValuesForDisplay returns the sorted expected values the field hasn't taken as comma separated string.
+func (f *FieldCoverage) GetMissingValuesForDisplay() string {
+	return strings.Join(f.Expected.Difference(f.Values).List(), ",")
+}
+
 // GetValues returns Values as slice
 func (f *FieldCoverage) GetValues() []string {
 	values := []string{}
@@ -47,9 +136,31 @@ func (f *FieldCoverage) GetValues() []string {
 	return values
 }
 
-// GetValuesForDisplay returns value strings as comma separated string.
+// GetValuesForDisplay returns value strings as comma separated string, each
+// followed by the number of times it was seen, if counted.
 func (f *FieldCoverage) GetValuesForDisplay() string {
-	return strings.Join(f.GetValues(), ",")
+	values := f.GetValues()
+	for i, value := range values {
+		if hits, ok := f.ValueHits[value]; ok {
+			values[i] = fmt.Sprintf("%s (%d)", value, hits)
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+// GetSeenForDisplay returns when the field was first and last covered.
+func (f *FieldCoverage) GetSeenForDisplay() string {
+	return fmt.Sprintf("first seen %s, last seen %s", f.FirstSeen.UTC().Format(time.RFC3339), f.LastSeen.UTC().Format(time.RFC3339))
+}
+
+// GetTestsForDisplay returns the sorted names of the tests that covered the field.
+func (f *FieldCoverage) GetTestsForDisplay() []string {
+	return f.Tests.List()
+}
+
+// GetOperationsForDisplay returns the sorted operations that covered the field as comma separated string.
+func (f *FieldCoverage) GetOperationsForDisplay() string {
+	return strings.Join(f.Operations.List(), ",")
 }
 
 // TypeCoverage encapsulates type information and field coverage.
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coveragecalculator
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxExemplars is the number of exemplars kept for each field.
+	MaxExemplars = 3
+)
+
+// Exemplar identifies an object whose request covered a field, eg: to find
+// the objects covering a field without grepping test logs.
+type Exemplar struct {
+	Namespace string    `json:"Namespace,omitempty"`
+	Name      string    `json:"Name,omitempty"`
+	UID       string    `json:"UID,omitempty"`
+	Operation string    `json:"Operation,omitempty"`
+	Time      time.Time `json:"Time"`
+}
+
+// String returns the exemplar for display, eg: CREATE default/foo (uid) at 2019-10-01T00:00:00Z.
+func (e Exemplar) String() string {
+	s := e.Name
+	if len(e.Namespace) != 0 {
+		s = e.Namespace + "/" + s
+	}
+	if len(e.UID) != 0 {
+		s += " (" + e.UID + ")"
+	}
+	if len(e.Operation) != 0 {
+		s = e.Operation + " " + s
+	}
+	return fmt.Sprintf("%s at %s", s, e.Time.UTC().Format(time.RFC3339))
+}
+
+// sameObject returns whether two exemplars are of the same object. Objects
+// are only known by name before they are created.
+func (e Exemplar) sameObject(other Exemplar) bool {
+	if len(e.UID) != 0 && len(other.UID) != 0 {
+		return e.UID == other.UID
+	}
+	return e.Namespace == other.Namespace && e.Name == other.Name
+}
+
+// AddExemplar returns exemplars with exemplar appended, unless they already
+// hold MaxExemplars, or one of the same object. The first objects to cover a
+// field are kept, rather than the latest, so the sample doesn't churn.
+func AddExemplar(exemplars []Exemplar, exemplar Exemplar) []Exemplar {
+	if len(exemplars) >= MaxExemplars {
+		return exemplars
+	}
+	for _, e := range exemplars {
+		if e.sameObject(exemplar) {
+			return exemplars
+		}
+	}
+	return append(exemplars, exemplar)
+}
@@ -39,7 +39,7 @@ type inputIgnoredFields struct {
 }
 
 // ReadFromFile is a utility method that can be used by repos to read .yaml input file into
-// IgnoredFields type.
+// IgnoredFields type. Fields are listed by their JSON name, as reports name them.
 func (ig *IgnoredFields) ReadFromFile(filePath string) error {
 	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
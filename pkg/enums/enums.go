@@ -0,0 +1,31 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package enums contains the values of the enum types of the Kubernetes API,
+// ie: of the types that exported typed constants are declared for, eg:
+// corev1.DNSPolicy, generated from the sources of the API packages.
+package enums
+
+import (
+	"reflect"
+)
+
+//go:generate go run ./gen -output zz_generated.enums.go
+
+// Values returns the values of an enum type, or nil if t is not one.
+func Values(t reflect.Type) []string {
+	return values[t.PkgPath()+"."+t.Name()]
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen generates the table of enum values for package enums, from the typed
+// constants declared by the API packages, eg:
+//
+//	const DNSClusterFirst DNSPolicy = "ClusterFirst"
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var (
+	outputFlag = flag.String("output", "", "file to write the table to (default: stdout)")
+	// Packages whose typed constants are enum values
+	packages = []string{
+		"k8s.io/api/...",
+		"k8s.io/apimachinery/pkg/apis/meta/v1",
+		"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1",
+	}
+	// Types whose constants are only well-known values of an open set, eg:
+	// the resource names of quotas, rather than all the values of an enum
+	excluded = sets.NewString(
+		"k8s.io/api/core/v1.FinalizerName",
+		"k8s.io/api/core/v1.ResourceName",
+	)
+)
+
+const header = `/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by gen. DO NOT EDIT.
+
+package enums
+
+// values maps the package path and name of each enum type to its values.
+var values = map[string][]string{
+`
+
+func main() {
+	flag.Parse()
+
+	// Packages are read from wherever the go command finds them, eg: the module cache
+	out, err := exec.Command("go", append([]string{"list", "-f", "{{.ImportPath}} {{.Dir}}"}, packages...)...).Output()
+	if err != nil {
+		log.Fatalf("Failed listing packages: %v", err)
+	}
+	enums := map[string]sets.String{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if err := addEnums(enums, fields[0], fields[1]); err != nil {
+			log.Fatalf("Failed reading enums of %s: %v", fields[0], err)
+		}
+	}
+
+	types := []string{}
+	for t := range enums {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	var buffer bytes.Buffer
+	buffer.WriteString(header)
+	for _, t := range types {
+		fmt.Fprintf(&buffer, "%q: {", t)
+		for _, value := range enums[t].List() {
+			fmt.Fprintf(&buffer, "%q, ", value)
+		}
+		buffer.WriteString("},\n")
+	}
+	buffer.WriteString("}\n")
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatalf("Failed formatting table: %v", err)
+	}
+
+	if *outputFlag == "" {
+		os.Stdout.Write(source)
+	} else if err := ioutil.WriteFile(*outputFlag, source, 0644); err != nil {
+		log.Fatalf("Failed writing %s: %v", *outputFlag, err)
+	}
+}
+
+// addEnums adds the literal values of the exported constants of a package that
+// are of a type declared by the package, keyed by the package path and type name.
+func addEnums(enums map[string]sets.String, importPath string, dir string) error {
+	fileSet := token.NewFileSet()
+	notTest := func(info os.FileInfo) bool { return !strings.HasSuffix(info.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fileSet, dir, notTest, 0)
+	if err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				// Some enums are declared as variables, eg: autoscaling's MetricSourceType
+				if !ok || (genDecl.Tok != token.CONST && genDecl.Tok != token.VAR) {
+					continue
+				}
+				for _, spec := range genDecl.Specs {
+					addEnumValues(enums, importPath, spec.(*ast.ValueSpec))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func addEnumValues(enums map[string]sets.String, importPath string, spec *ast.ValueSpec) {
+	// Types of other packages are qualified, ie: not an *ast.Ident, and
+	// builtin types are not exported
+	typeName, ok := spec.Type.(*ast.Ident)
+	if !ok || !ast.IsExported(typeName.Name) {
+		return
+	}
+	key := importPath + "." + typeName.Name
+	if excluded.Has(key) {
+		return
+	}
+	for i, name := range spec.Names {
+		if !name.IsExported() || i >= len(spec.Values) {
+			continue
+		}
+		literal, ok := spec.Values[i].(*ast.BasicLit)
+		if !ok {
+			continue
+		}
+		value := literal.Value
+		if literal.Kind == token.STRING {
+			unquoted, err := strconv.Unquote(literal.Value)
+			if err != nil {
+				continue
+			}
+			value = unquoted
+		}
+		if _, ok := enums[key]; !ok {
+			enums[key] = sets.String{}
+		}
+		enums[key].Insert(value)
+	}
+}
@@ -3,6 +3,7 @@ package kube
 import (
 	"fmt"
 
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -12,6 +13,32 @@ import (
 // - first try the in-cluster config
 // - next try the default rules (KUBECONFIG, .kube/config)
 func BuildKubeClient() (kubernetes.Interface, error) {
+	clientConfig, err := buildClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := kubernetes.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to make client from config: %v", err)
+	}
+	return client, nil
+}
+
+// BuildAPIExtensionsClient returns an apiextensions client, eg: to list
+// CustomResourceDefinitions, loaded via the same rules as BuildKubeClient.
+func BuildAPIExtensionsClient() (apiextensionsclientset.Interface, error) {
+	clientConfig, err := buildClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := apiextensionsclientset.NewForConfig(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to make apiextensions client from config: %v", err)
+	}
+	return client, nil
+}
+
+func buildClientConfig() (*rest.Config, error) {
 	clientConfig, err := rest.InClusterConfig()
 	if err != nil {
 		config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
@@ -23,9 +50,5 @@ func BuildKubeClient() (kubernetes.Interface, error) {
 			return nil, fmt.Errorf("could not load client configuration: %v", err)
 		}
 	}
-	client, err := kubernetes.NewForConfig(clientConfig)
-	if err != nil {
-		return nil, fmt.Errorf("unable to make client from config: %v", err)
-	}
-	return client, nil
+	return clientConfig, nil
 }
@@ -51,13 +51,42 @@ func (a *ArrayKindNode) buildChildNodes(t reflect.Type) {
 	childNode := a.Tree.createNode(childName, a, t.Elem())
 	a.Children[childName] = childNode
 	childNode.buildChildNodes(t.Elem())
+	// Elements of an array of enums are expected to take the values of the enum
+	a.Expected = childNode.GetData().Expected
 }
 
-func (a *ArrayKindNode) updateCoverage(v reflect.Value) {
+func (a *ArrayKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	if !v.IsNil() {
-		a.Covered = true
+		a.markCovered(updateHelper)
 		for i := 0; i < v.Len(); i++ {
-			a.Children[a.Field+arrayNodeNameSuffix].updateCoverage(v.Index(i))
+			a.Children[a.Field+arrayNodeNameSuffix].updateCoverage(v.Index(i), updateHelper)
+		}
+	}
+}
+
+func (a *ArrayKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	// Elements are compared by index, so reordering mutates them
+	mutated := v.Len() != old.Len() || v.IsNil() != old.IsNil()
+	for i := 0; i < v.Len() || i < old.Len(); i++ {
+		if a.Children[a.Field+arrayNodeNameSuffix].updateMutation(indexOrZero(v, i), indexOrZero(old, i)) {
+			mutated = true
+		}
+	}
+	if mutated {
+		a.markMutated()
+	}
+	return mutated
+}
+
+func (a *ArrayKindNode) updatePresence(raw reflect.Value) {
+	a.markPresent()
+	// eg: []byte, whose JSON is a base64 string
+	if raw.Kind() != reflect.Slice {
+		return
+	}
+	for i := 0; i < raw.Len(); i++ {
+		if value := rawElem(raw.Index(i)); value.IsValid() {
+			a.Children[a.Field+arrayNodeNameSuffix].updatePresence(value)
 		}
 	}
 }
@@ -68,6 +97,12 @@ func (a *ArrayKindNode) buildCoverageData(coverageHelper coverageDataHelper) {
 	}
 }
 
+// getValues returns the values of the elements, as an array of enums is
+// covered by the values of its elements.
 func (a *ArrayKindNode) getValues() sets.String {
-	return nil
+	return a.Children[a.Field+arrayNodeNameSuffix].getValues()
+}
+
+func (a *ArrayKindNode) getValueHits() map[string]int {
+	return getValueHits(a.Children[a.Field+arrayNodeNameSuffix])
 }
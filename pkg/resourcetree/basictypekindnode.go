@@ -22,6 +22,7 @@ import (
 	"strconv"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/k8s-api-coverage/pkg/enums"
 )
 
 var _ NodeInterface = &BasicTypeKindNode{}
@@ -29,8 +30,8 @@ var _ NodeInterface = &BasicTypeKindNode{}
 // BasicTypeKindNode represents resource tree node of basic types like int, float, etc.
 type BasicTypeKindNode struct {
 	NodeData
-	values       sets.String // Values seen for this node. Useful for enum types.
-	possibleEnum bool        // Flag to indicate if this is a possible enum.
+	values       valueHits // Values seen for this node, and how many times. Useful for enum types.
+	possibleEnum bool      // Flag to indicate if this is a possible enum.
 }
 
 // GetData returns node data
@@ -40,7 +41,7 @@ func (b *BasicTypeKindNode) GetData() NodeData {
 
 func (b *BasicTypeKindNode) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
 	b.NodeData.initialize(field, parent, t, rt)
-	b.values = sets.String{}
+	b.values = valueHits{}
 	b.NodeData.LeafNode = true
 }
 
@@ -49,18 +50,34 @@ func (b *BasicTypeKindNode) buildChildNodes(t reflect.Type) {
 	if t.Name() != t.Kind().String() || b.FieldType.Kind() == reflect.Bool {
 		b.possibleEnum = true
 	}
+	// Types the API declares constants of are known to be enums, of those values
+	if values := enums.Values(t); len(values) != 0 {
+		b.Expected = sets.NewString(values...)
+	}
 }
 
-func (b *BasicTypeKindNode) updateCoverage(v reflect.Value) {
+func (b *BasicTypeKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	value := b.string(v)
 	// There are some enums that use "" as an explicit value ...
 	if b.possibleEnum || b.FieldType.Kind() == reflect.Bool {
-		b.values.Insert(value)
+		b.values[value]++
 	}
 	// ... but let's not assume coverage until a non-empty value is added
 	if len(value) > 0 {
-		b.Covered = true
+		b.markCovered(updateHelper)
+	}
+}
+
+func (b *BasicTypeKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	if valuesEqual(v, old) {
+		return false
 	}
+	b.markMutated()
+	return true
+}
+
+func (b *BasicTypeKindNode) updatePresence(raw reflect.Value) {
+	b.markPresent()
 }
 
 // no-op as the coverage is calculated as field coverage in parent node.
@@ -91,7 +108,26 @@ func (b *BasicTypeKindNode) string(v reflect.Value) string {
 	return ""
 }
 
+func (b *BasicTypeKindNode) coverageState() NodeCoverageState {
+	state := b.NodeData.coverageState()
+	b.values.coverageState(&state)
+	return state
+}
+
+func (b *BasicTypeKindNode) restoreCoverageState(state NodeCoverageState) {
+	b.NodeData.restoreCoverageState(state)
+	b.values.restoreCoverageState(state)
+}
+
 func (b *BasicTypeKindNode) getValues() sets.String {
+	if b.possibleEnum {
+		return sets.StringKeySet(b.values)
+	}
+
+	return nil
+}
+
+func (b *BasicTypeKindNode) getValueHits() map[string]int {
 	if b.possibleEnum {
 		return b.values
 	}
@@ -0,0 +1,321 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"container/list"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+)
+
+// TestConcurrentUpdateAndBuildCoverageData is meant to be run with -race, it
+// mimics the webhook updating coverage while reports are being fetched.
+func TestConcurrentUpdateAndBuildCoverageData(t *testing.T) {
+	tree := getTestTree(arrayTypeName, reflect.TypeOf(arrayType{}))
+	forest := tree.Forest
+	forest.AddResourceTree(ptrTypeName, ptrTypeName, reflect.TypeOf(ptrType{}))
+	arrTree := forest.TopLevelTrees[arrayTypeName]
+	ptrTree := forest.TopLevelTrees[ptrTypeName]
+
+	const iterations = 100
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			arrTree.UpdateCoverageFromRequest(reflect.ValueOf(getArrValueAllCovered()), RequestInfo{Test: fmt.Sprintf("arr-%d", i)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			ptrTree.UpdateCoverageFromRequest(reflect.ValueOf(getPtrTypeValueAllCovered()), RequestInfo{Test: fmt.Sprintf("ptr-%d", i)})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < iterations; i++ {
+			// baseType is connected across both trees
+			arrTree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+			ptrTree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+			forest.Tests()
+		}
+	}()
+	wg.Wait()
+
+	if tests := forest.Tests(); tests.Len() != 2*iterations {
+		t.Fatalf("Expected %d tests, found: %d", 2*iterations, tests.Len())
+	}
+	typeCoverage := arrTree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if coverage := coveragecalculator.CalculateTypeCoverage(typeCoverage); coverage.CoveredFields != coverage.TotalFields {
+		t.Fatalf("Expected all %d fields covered, found: %d", coverage.TotalFields, coverage.CoveredFields)
+	}
+}
+
+func TestBuildCoverageDataAllTypes(t *testing.T) {
+	tree := getTestTree(ptrTypeName, reflect.TypeOf(ptrType{}))
+	allTypes := CoverageOptions{AllTypes: true}
+	// structPtr's baseType is only counted once it is covered, unless all types are
+	before := coveragecalculator.CalculateTypeCoverage(tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{}))
+	if before.TotalFields != 2 {
+		t.Errorf("Expected the 2 fields of ptrType, found: %d", before.TotalFields)
+	}
+	beforeAll := coveragecalculator.CalculateTypeCoverage(tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, allTypes))
+	if beforeAll.TotalFields != 4 || beforeAll.CoveredFields != 0 {
+		t.Errorf("Expected the 4 fields of ptrType and baseType, none covered, found: %+v", beforeAll)
+	}
+
+	tree.UpdateCoverage(reflect.ValueOf(getPtrTypeValueAllCovered()))
+	afterAll := coveragecalculator.CalculateTypeCoverage(tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, allTypes))
+	if afterAll.TotalFields != beforeAll.TotalFields || afterAll.CoveredFields != 3 {
+		t.Errorf("Expected the same %d fields, all but baseType.field2 covered, found: %+v", beforeAll.TotalFields, afterAll)
+	}
+	// A test that sent nothing is still outlined
+	testAll := coveragecalculator.CalculateTypeCoverage(tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Test: "other", AllTypes: true}))
+	if testAll.TotalFields != beforeAll.TotalFields || testAll.CoveredFields != 0 {
+		t.Errorf("Expected the same %d fields, none covered by test other, found: %+v", beforeAll.TotalFields, testAll)
+	}
+}
+
+type pathType struct {
+	first  baseType
+	second *baseType
+	third  []baseType
+}
+
+func TestBuildPathCoverageData(t *testing.T) {
+	tree := getTestTree("pathType", reflect.TypeOf(pathType{}))
+	tree.UpdateCoverage(reflect.ValueOf(pathType{first: baseType{field1: "a"}, third: []baseType{{field2: 1}}}))
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if base := getTypeCoverage(typeCoverage, "baseType"); base == nil || !base.Fields["field1"].Coverage || !base.Fields["field2"].Coverage {
+		t.Errorf("Expected baseType coverage merged across paths, found: %+v", base)
+	}
+
+	pathCoverage := tree.BuildPathCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if len(pathCoverage) != 3 {
+		t.Errorf("Expected pathType, pathType.first and pathType.third[] outlined, found: %+v", pathCoverage)
+	}
+	if first := getTypeCoverage(pathCoverage, "pathType.first"); first == nil || !first.Fields["field1"].Coverage || first.Fields["field2"].Coverage {
+		t.Errorf("Expected only field1 covered at pathType.first, found: %+v", first)
+	}
+	if third := getTypeCoverage(pathCoverage, "pathType.third[]"); third == nil || third.Fields["field1"].Coverage || !third.Fields["field2"].Coverage {
+		t.Errorf("Expected only field2 covered at pathType.third[], found: %+v", third)
+	}
+
+	pathCoverage = tree.BuildPathCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{AllTypes: true})
+	if second := getTypeCoverage(pathCoverage, "pathType.second"); second == nil || second.Fields["field1"].Coverage || second.Fields["field2"].Coverage {
+		t.Errorf("Expected nothing covered at pathType.second, found: %+v", second)
+	}
+}
+
+func TestBuildCoverageDataJSONNames(t *testing.T) {
+	tree := getTestTree("presenceType", reflect.TypeOf(presenceType{}))
+	tree.UpdateCoverage(reflect.ValueOf(presenceType{presenceMeta: presenceMeta{Kind: "K"}, Items: []presenceItem{{Name: "item"}}}))
+
+	item := tree.Root.GetData().Children["Items"].GetData().Children["Items-arr"].GetData()
+	if path := item.Children["Name"].GetData().JSONPath; path != "items[].name" {
+		t.Errorf("Expected JSONPath items[].name, found: %s", path)
+	}
+	if path := tree.Root.GetData().Children["presenceMeta"].GetData().Children["Kind"].GetData().JSONPath; path != "kind" {
+		t.Errorf("Expected JSONPath of an inlined field kind, found: %s", path)
+	}
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	root := getTypeCoverage(typeCoverage, "presenceType")
+	if root == nil || len(root.Fields) != 6 || !root.Fields["kind"].Coverage || !root.Fields["items"].Coverage || root.Fields["name"] == nil {
+		t.Errorf("Expected fields of presenceType by JSON name, with presenceMeta inlined, found: %+v", root)
+	}
+	if getTypeCoverage(typeCoverage, "presenceMeta") != nil {
+		t.Errorf("Expected inlined presenceMeta not outlined")
+	}
+
+	pathCoverage := tree.BuildPathCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if items := getTypeCoverage(pathCoverage, "presenceType.items[]"); items == nil || !items.Fields["name"].Coverage {
+		t.Errorf("Expected name covered at presenceType.items[], found: %+v", pathCoverage)
+	}
+}
+
+func TestBuildCoverageDataIgnoredFields(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignoredfields")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Ignored by the Go name of a field, and by a field of an inlined struct
+	path := filepath.Join(dir, "ignoredfields.yaml")
+	content := `
+- package: resourcetree
+  type: presenceType
+  fields:
+    - Enabled
+- package: resourcetree
+  type: presenceMeta
+  fields:
+    - kind
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ignoredFields := coveragecalculator.IgnoredFields{}
+	if err := ignoredFields.ReadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tree := getTestTree("presenceType", reflect.TypeOf(presenceType{}))
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, ignoredFields, CoverageOptions{AllTypes: true})
+	root := getTypeCoverage(typeCoverage, "presenceType")
+	if root == nil {
+		t.Fatalf("Expected coverage of presenceType")
+	}
+	for field, ignored := range map[string]bool{"enabled": true, "kind": true, "name": false} {
+		if root.Fields[field].Ignored != ignored {
+			t.Errorf("Expected presenceType.%s Ignored to be %t", field, ignored)
+		}
+	}
+}
+
+type resourceMap map[string]int64
+
+type mapType struct {
+	Labels    map[string]string   `json:"labels"`
+	Resources resourceMap         `json:"resources"`
+	Items     map[string]baseType `json:"items"`
+}
+
+func TestBuildCoverageDataMapKeys(t *testing.T) {
+	pkg := reflect.TypeOf(mapType{}).PkgPath()
+	forest := ResourceForest{
+		ConnectedNodes: make(map[string]*list.List),
+		TopLevelTrees:  make(map[string]ResourceTree),
+		ExpectedMapKeys: map[string][]string{
+			pkg + ".resourceMap":    {"cpu", "memory"},
+			pkg + ".mapType.labels": {"app"},
+		},
+	}
+	forest.AddResourceTree("mapType", "mapType", reflect.TypeOf(mapType{}))
+	tree := forest.TopLevelTrees["mapType"]
+	old := mapType{Labels: map[string]string{"app": "a"}, Resources: resourceMap{"cpu": 1}}
+	updated := mapType{Labels: map[string]string{"app": "a"}, Resources: resourceMap{"cpu": 2}, Items: map[string]baseType{"x": {field1: "a"}}}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(old), RequestInfo{})
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(updated), RequestInfo{OldObject: reflect.ValueOf(old)})
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	root := getTypeCoverage(typeCoverage, "mapType")
+	if root == nil {
+		t.Fatalf("Expected coverage of mapType")
+	}
+	if labels := root.Fields["labels"]; !labels.Values.Has("app") || labels.Partial() || labels.Mutated {
+		t.Errorf("Expected labels covered with all expected keys, and not mutated, found: %+v", labels)
+	}
+	if resources := root.Fields["resources"]; !resources.Partial() || resources.GetMissingValuesForDisplay() != "memory" || !resources.Mutated {
+		t.Errorf("Expected resources partially covered, missing memory, and mutated, found: %+v", resources)
+	}
+	if values := coveragecalculator.CalculateTypeCoverage(typeCoverage); values.PartialFields != 1 {
+		t.Errorf("Expected 1 partially covered field, found: %d", values.PartialFields)
+	}
+	if items := getTypeCoverage(typeCoverage, "baseType"); items == nil || !items.Fields["field1"].Coverage {
+		t.Errorf("Expected the struct values of items outlined, found: %+v", typeCoverage)
+	}
+}
+
+type recursiveType struct {
+	Name     string          `json:"name"`
+	Children []recursiveType `json:"children"`
+	Next     *recursiveType  `json:"next"`
+}
+
+func TestBuildCoverageDataRecursiveType(t *testing.T) {
+	tree := getTestTree("recursiveType", reflect.TypeOf(recursiveType{}))
+	children := tree.Root.GetData().Children["Children"].GetData().Children["Children-arr"]
+	if reference, ok := children.(*ReferenceNode); !ok || reference.Target != tree.Root {
+		t.Fatalf("Expected Children-arr a reference to the root, found: %T", children)
+	}
+
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(recursiveType{Name: "a", Children: []recursiveType{{Next: &recursiveType{Name: "c"}}}}), RequestInfo{Test: "test-a"})
+	// Coverage of the children's Next is recorded in the root's
+	if next := tree.Root.GetData().Children["Next"].GetData(); !next.Covered || !next.Tests.Has("test-a") {
+		t.Errorf("Expected Next covered through Children by test-a, found: %+v", next)
+	}
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if len(typeCoverage) != 1 {
+		t.Fatalf("Expected recursiveType outlined once, found: %+v", typeCoverage)
+	}
+	for _, field := range []string{"name", "children", "next"} {
+		if !typeCoverage[0].Fields[field].Coverage {
+			t.Errorf("Expected recursiveType.%s covered", field)
+		}
+	}
+
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(recursiveType{Name: "b"}), RequestInfo{OldObject: reflect.ValueOf(recursiveType{Name: "a"})})
+	if name := tree.Root.GetData().Children["Name"].GetData(); !name.Mutated {
+		t.Errorf("Expected Name mutated, found: %+v", name)
+	}
+	if children := tree.Root.GetData().Children["Children"].GetData(); children.Mutated {
+		t.Errorf("Expected Children not mutated, found: %+v", children)
+	}
+}
+
+type enumType struct {
+	DNSPolicy        corev1.DNSPolicy                    `json:"dnsPolicy"`
+	MountPropagation *corev1.MountPropagationMode        `json:"mountPropagation"`
+	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes"`
+	Name             string                              `json:"name"`
+}
+
+func TestBuildCoverageDataKnownEnums(t *testing.T) {
+	tree := getTestTree("enumType", reflect.TypeOf(enumType{}))
+	mountPropagation := corev1.MountPropagationHostToContainer
+	tree.UpdateCoverage(reflect.ValueOf(enumType{
+		DNSPolicy:        corev1.DNSClusterFirst,
+		MountPropagation: &mountPropagation,
+		AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+		Name:             "a",
+	}))
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	root := getTypeCoverage(typeCoverage, "enumType")
+	if root == nil {
+		t.Fatalf("Expected coverage of enumType")
+	}
+	dnsPolicy := root.Fields["dnsPolicy"]
+	if dnsPolicy.Expected.Len() != 4 || dnsPolicy.GetExpectedSeen() != 1 || !dnsPolicy.Partial() {
+		t.Fatalf("Expected dnsPolicy partially covered, 1 of 4 values seen, found: %+v", dnsPolicy)
+	}
+	if missing := dnsPolicy.GetMissingValuesForDisplay(); missing != "ClusterFirstWithHostNet,Default,None" {
+		t.Errorf("Expected missing values ClusterFirstWithHostNet,Default,None, found: %s", missing)
+	}
+	// Enums behind a pointer or in an array are covered by their values
+	if mountPropagation := root.Fields["mountPropagation"]; mountPropagation.Expected.Len() != 3 || mountPropagation.GetExpectedSeen() != 1 {
+		t.Errorf("Expected mountPropagation 1 of 3 values seen, found: %+v", mountPropagation)
+	}
+	if accessModes := root.Fields["accessModes"]; accessModes.Expected.Len() != 3 || accessModes.GetExpectedSeen() != 2 || accessModes.ValueHits[string(corev1.ReadWriteOnce)] != 1 {
+		t.Errorf("Expected accessModes 2 of 3 values seen, found: %+v", accessModes)
+	}
+	if name := root.Fields["name"]; name.Expected.Len() != 0 || name.Partial() {
+		t.Errorf("Expected no expected values for name, found: %+v", name)
+	}
+}
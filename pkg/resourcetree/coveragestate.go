@@ -0,0 +1,99 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"time"
+
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+)
+
+// coveragestate.go contains types and methods to snapshot and restore the
+// coverage recorded in a resource forest, e.g. across restarts of the webhook.
+
+// CoverageState is a serializable snapshot of the coverage recorded in a
+// ResourceForest. It is keyed by the TopLevelTrees key of each tree, then by
+// NodeData.NodePath, and only contains nodes that have recorded anything.
+type CoverageState map[string]map[string]NodeCoverageState
+
+// NodeCoverageState is the coverage recorded for a single node.
+type NodeCoverageState struct {
+	Covered    bool     `json:"covered,omitempty"`
+	Mutated    bool     `json:"mutated,omitempty"`
+	Present    bool     `json:"present,omitempty"`
+	Tests      []string `json:"tests,omitempty"`
+	Operations []string `json:"operations,omitempty"`
+	Values     []string `json:"values,omitempty"`
+	// ValueHits is how many times each of Values was seen, for nodes that count them.
+	ValueHits map[string]int                `json:"valueHits,omitempty"`
+	Hits      int                           `json:"hits,omitempty"`
+	FirstSeen *time.Time                    `json:"firstSeen,omitempty"`
+	LastSeen  *time.Time                    `json:"lastSeen,omitempty"`
+	Exemplars []coveragecalculator.Exemplar `json:"exemplars,omitempty"`
+}
+
+func (n NodeCoverageState) isEmpty() bool {
+	return !n.Covered && !n.Mutated && !n.Present && len(n.Tests) == 0 && len(n.Operations) == 0 && len(n.Values) == 0 && n.Hits == 0
+}
+
+// GetCoverageState returns a snapshot of the coverage recorded in the forest.
+func (r *ResourceForest) GetCoverageState() CoverageState {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	state := CoverageState{}
+	for key, tree := range r.TopLevelTrees {
+		treeState := map[string]NodeCoverageState{}
+		getNodeCoverageState(tree.Root, treeState)
+		if len(treeState) != 0 {
+			state[key] = treeState
+		}
+	}
+	return state
+}
+
+// RestoreCoverageState merges a snapshot taken by GetCoverageState into the
+// coverage recorded in the forest. Trees and nodes that no longer exist in
+// the forest are skipped.
+func (r *ResourceForest) RestoreCoverageState(state CoverageState) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for key, treeState := range state {
+		if tree, ok := r.TopLevelTrees[key]; ok {
+			restoreNodeCoverageState(tree.Root, treeState)
+		}
+	}
+}
+
+func getNodeCoverageState(node NodeInterface, treeState map[string]NodeCoverageState) {
+	if nodeState := node.coverageState(); !nodeState.isEmpty() {
+		treeState[node.GetData().NodePath] = nodeState
+	}
+	for _, child := range node.GetData().Children {
+		getNodeCoverageState(child, treeState)
+	}
+}
+
+func restoreNodeCoverageState(node NodeInterface, treeState map[string]NodeCoverageState) {
+	if nodeState, ok := treeState[node.GetData().NodePath]; ok {
+		node.restoreCoverageState(nodeState)
+	}
+	for _, child := range node.GetData().Children {
+		restoreNodeCoverageState(child, treeState)
+	}
+}
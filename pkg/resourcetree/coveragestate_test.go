@@ -0,0 +1,46 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCoverageStateRoundTrip(t *testing.T) {
+	tree := getTestTree(arrayTypeName, reflect.TypeOf(arrayType{}))
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(getArrValueSomeCovered()), RequestInfo{Test: "test-a", Operation: "UPDATE", OldObject: reflect.ValueOf(arrayType{})})
+
+	data, err := json.Marshal(tree.Forest.GetCoverageState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := CoverageState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := getTestTree(arrayTypeName, reflect.TypeOf(arrayType{}))
+	restored.Forest.RestoreCoverageState(state)
+	if err := verifyArrValueSomeCovered(restored.Root); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Forest.GetCoverageState(), state) {
+		t.Fatalf("Restored coverage state differs, expected: %v found: %v", state, restored.Forest.GetCoverageState())
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	intOrStringInt    = "Int"
+	intOrStringString = "String"
+)
+
+var intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+
+var _ NodeInterface = &IntOrStringTypeNode{}
+
+// IntOrStringTypeNode is a node type that encapsulates intstr.IntOrString
+// fields, e.g. the port of a corev1.HTTPGetAction, which can be specified as
+// a number or a name. Their values are specified as either, rather than as a
+// struct, so we create IntOrStringTypeNodes and mark them as leafnodes. The
+// form a value was specified in, Int or String, is recorded as its value.
+type IntOrStringTypeNode struct {
+	valuesLeafNode // Forms seen for this node.
+}
+
+func (i *IntOrStringTypeNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if !v.CanInterface() {
+		return
+	}
+	// Like other values, let's not assume coverage of 0 or ""
+	switch value := v.Interface().(intstr.IntOrString); {
+	case value.Type == intstr.Int && value.IntVal != 0:
+		i.values.Insert(intOrStringInt)
+		i.markCovered(updateHelper)
+	case value.Type == intstr.String && len(value.StrVal) != 0:
+		i.values.Insert(intOrStringString)
+		i.markCovered(updateHelper)
+	}
+}
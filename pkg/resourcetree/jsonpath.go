@@ -0,0 +1,121 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+// jsonpath.go contains helpers to name nodes the way their resource is
+// encoded as JSON, eg: spec.containers[].livenessProbe, which is how fields
+// are referred to by kubectl explain and the API reference docs.
+
+import (
+	"reflect"
+	"strings"
+)
+
+// jsonFieldName returns the key of a struct field in JSON, and whether the
+// field is inlined into its parent instead, eg: TypeMeta. Fields that are
+// not encoded, eg: unexported fields, keep their Go name.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if field.Anonymous && len(name) == 0 {
+		return "", true
+	}
+	if len(name) == 0 || name == "-" {
+		return field.Name, false
+	}
+	return name, false
+}
+
+// jsonEncoded returns whether a struct field is encoded in JSON.
+func jsonEncoded(field reflect.StructField) bool {
+	return len(field.PkgPath) == 0 && field.Tag.Get("json") != "-"
+}
+
+// childJSONPath returns the JSON path of the child of parent at field, eg:
+// spec.containers[] for the elements of spec.containers.
+func childJSONPath(parent NodeInterface, field string) string {
+	data := parent.GetData()
+	if s, ok := parent.(*SchemaNode); ok {
+		switch s.kind {
+		case schemaArray:
+			return data.JSONPath + "[]"
+		case schemaMap:
+			return data.JSONPath + "{}"
+		}
+		return joinJSONPath(data.JSONPath, field)
+	}
+
+	switch data.FieldType.Kind() {
+	case reflect.Array, reflect.Slice:
+		return data.JSONPath + "[]"
+	case reflect.Map:
+		return data.JSONPath + "{}"
+	case reflect.Struct:
+		if structField, ok := data.FieldType.FieldByName(field); ok {
+			name, inline := jsonFieldName(structField)
+			if inline {
+				return data.JSONPath
+			}
+			return joinJSONPath(data.JSONPath, name)
+		}
+	}
+	// Pointers share the path of what they point to
+	return data.JSONPath
+}
+
+func joinJSONPath(path string, name string) string {
+	if len(path) == 0 {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonPathName returns the JSON path of a node as it's displayed, ie: prefixed
+// with the name of its resource, eg: Pod.spec.containers[].
+func jsonPathName(nd NodeData) string {
+	return joinJSONPath(nd.Tree.ResourceName, nd.JSONPath)
+}
+
+// jsonFields returns the children of a node keyed by their JSON field name,
+// with the fields of inlined structs, eg: TypeMeta, in place of the struct.
+func jsonFields(node NodeInterface) map[string]NodeInterface {
+	fields := make(map[string]NodeInterface)
+	s, ok := node.(*StructKindNode)
+	if !ok || s.LeafNode {
+		for field, child := range node.GetData().Children {
+			fields[field] = child
+		}
+		return fields
+	}
+
+	for i := 0; i < s.FieldType.NumField(); i++ {
+		field := s.FieldType.Field(i)
+		child := s.Children[field.Name]
+		name, inline := jsonFieldName(field)
+		if !inline {
+			fields[name] = child
+			continue
+		}
+		// eg: an embedded *Foo
+		if ptr, ok := child.(*PtrKindNode); ok {
+			child = ptr.Children[ptr.Field+ptrNodeNameSuffix]
+		}
+		for name, child := range jsonFields(child) {
+			fields[name] = child
+		}
+	}
+	return fields
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var _ NodeInterface = &MapKindNode{}
+
+// MapKindNode represents resource tree nodes of type reflect.Kind.Map. Values
+// of the map are represented by a single child node, like the elements of an
+// array, and the keys seen are recorded as the node's values, eg: label keys,
+// or the resource names of a ResourceList.
+type MapKindNode struct {
+	NodeData
+	// Map type e.g. map[string]int will store reflect.Kind.Int.
+	// This is required for type-expansion and value-evaluation decisions.
+	valueKind reflect.Kind
+	keys      sets.String // Keys seen for this node.
+}
+
+// GetData returns node data
+func (m *MapKindNode) GetData() NodeData {
+	return m.NodeData
+}
+
+func (m *MapKindNode) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
+	m.NodeData.initialize(field, parent, t, rt)
+	m.valueKind = t.Elem().Kind()
+	m.keys = sets.String{}
+	if expected := m.expectedKeys(); len(expected) != 0 {
+		m.Expected = sets.NewString(expected...)
+	}
+}
+
+// expectedKeys returns the well-known keys of the map, from the forest's
+// ExpectedMapKeys, by the field holding the map or else by the map's type.
+func (m *MapKindNode) expectedKeys() []string {
+	expectedMapKeys := m.Tree.Forest.ExpectedMapKeys
+	// Pointers to the map share its field
+	field, parent := m.Field, m.Parent
+	for p, ok := parent.(*PtrKindNode); ok; p, ok = parent.(*PtrKindNode) {
+		field, parent = p.Field, p.Parent
+	}
+	if s, ok := parent.(*StructKindNode); ok {
+		if structField, ok := s.FieldType.FieldByName(field); ok {
+			name, _ := jsonFieldName(structField)
+			if keys, ok := expectedMapKeys[s.FieldType.PkgPath()+"."+s.FieldType.Name()+"."+name]; ok {
+				return keys
+			}
+		}
+	}
+	if len(m.FieldType.PkgPath()) != 0 {
+		return expectedMapKeys[m.FieldType.PkgPath()+"."+m.FieldType.Name()]
+	}
+	return nil
+}
+
+func (m *MapKindNode) buildChildNodes(t reflect.Type) {
+	childName := m.Field + mapNodeNameSuffix
+	childNode := m.Tree.createNode(childName, m, t.Elem())
+	m.Children[childName] = childNode
+	childNode.buildChildNodes(t.Elem())
+}
+
+func (m *MapKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if !v.IsNil() {
+		m.markCovered(updateHelper)
+		for _, key := range v.MapKeys() {
+			m.keys.Insert(mapKeyString(key))
+			m.Children[m.Field+mapNodeNameSuffix].updateCoverage(v.MapIndex(key), updateHelper)
+		}
+	}
+}
+
+func (m *MapKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	// Values are compared by key, so adding or removing a key mutates the map
+	mutated := v.IsNil() != old.IsNil() || !valuesEqual(v, old)
+	keys := v.MapKeys()
+	for _, key := range old.MapKeys() {
+		if !v.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		value := valueOrZero(v.MapIndex(key), m.FieldType.Elem())
+		oldValue := valueOrZero(old.MapIndex(key), m.FieldType.Elem())
+		if m.Children[m.Field+mapNodeNameSuffix].updateMutation(value, oldValue) {
+			mutated = true
+		}
+	}
+	if mutated {
+		m.markMutated()
+	}
+	return mutated
+}
+
+func (m *MapKindNode) updatePresence(raw reflect.Value) {
+	m.markPresent()
+	if raw.Kind() != reflect.Map {
+		return
+	}
+	for _, key := range raw.MapKeys() {
+		if value := rawElem(raw.MapIndex(key)); value.IsValid() {
+			m.Children[m.Field+mapNodeNameSuffix].updatePresence(value)
+		}
+	}
+}
+
+// mapKeyString returns the string form of a map key, as it's keyed in JSON.
+func mapKeyString(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	return fmt.Sprint(key)
+}
+
+func (m *MapKindNode) buildCoverageData(coverageHelper coverageDataHelper) {
+	if m.valueKind == reflect.Struct {
+		m.Children[m.Field+mapNodeNameSuffix].buildCoverageData(coverageHelper)
+	}
+}
+
+func (m *MapKindNode) getValues() sets.String {
+	return m.keys
+}
+
+func (m *MapKindNode) coverageState() NodeCoverageState {
+	state := m.NodeData.coverageState()
+	if m.keys.Len() != 0 {
+		state.Values = m.keys.List()
+	}
+	return state
+}
+
+func (m *MapKindNode) restoreCoverageState(state NodeCoverageState) {
+	m.NodeData.restoreCoverageState(state)
+	m.keys.Insert(state.Values...)
+}
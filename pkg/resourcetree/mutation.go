@@ -0,0 +1,112 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+// mutation.go contains helpers to tell which fields of a resource an update
+// actually changed, by comparing the new value of each node to the old value.
+
+import (
+	"reflect"
+)
+
+// markMutated marks the node as mutated by an update.
+func (nd *NodeData) markMutated() {
+	nd.Mutated = true
+}
+
+// valueOrZero returns v, or the zero value of t if v is invalid, eg: the
+// element of a nil pointer, or past the end of the shorter of two slices.
+func valueOrZero(v reflect.Value, t reflect.Type) reflect.Value {
+	if !v.IsValid() {
+		return reflect.Zero(t)
+	}
+	return v
+}
+
+// elemOrZero returns the element v points to, or its zero value if v is nil.
+func elemOrZero(v reflect.Value) reflect.Value {
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Elem()
+}
+
+// indexOrZero returns the element of a slice or array at index i, or the
+// zero value of its elements if i is out of range.
+func indexOrZero(v reflect.Value, i int) reflect.Value {
+	if i >= v.Len() {
+		return reflect.Zero(v.Type().Elem())
+	}
+	return v.Index(i)
+}
+
+// valuesEqual is like reflect.DeepEqual, except that it compares values that
+// need not be exported, eg: values of fields of an unexported struct.
+func valuesEqual(v1 reflect.Value, v2 reflect.Value) bool {
+	if !v1.IsValid() || !v2.IsValid() {
+		return v1.IsValid() == v2.IsValid()
+	}
+	if v1.Type() != v2.Type() {
+		return false
+	}
+	switch v1.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v1.IsNil() || v2.IsNil() {
+			return v1.IsNil() == v2.IsNil()
+		}
+		return valuesEqual(v1.Elem(), v2.Elem())
+	case reflect.Struct:
+		for i := 0; i < v1.NumField(); i++ {
+			if !valuesEqual(v1.Field(i), v2.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if v1.Len() != v2.Len() {
+			return false
+		}
+		for i := 0; i < v1.Len(); i++ {
+			if !valuesEqual(v1.Index(i), v2.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if v1.Len() != v2.Len() {
+			return false
+		}
+		for _, key := range v1.MapKeys() {
+			if !valuesEqual(v1.MapIndex(key), v2.MapIndex(key)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return v1.Bool() == v2.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v1.Int() == v2.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v1.Uint() == v2.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v1.Float() == v2.Float()
+	case reflect.String:
+		return v1.String() == v2.String()
+	}
+	// Funcs, channels and unsafe pointers don't appear in API types
+	return true
+}
@@ -20,8 +20,10 @@ package resourcetree
 
 import (
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 )
 
 // NodeInterface defines methods that can be performed on each node in the resource tree.
@@ -29,9 +31,13 @@ type NodeInterface interface {
 	GetData() NodeData
 	initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree)
 	buildChildNodes(t reflect.Type)
-	updateCoverage(v reflect.Value)
+	updateCoverage(v reflect.Value, updateHelper updateCoverageHelper)
+	updateMutation(v reflect.Value, old reflect.Value) bool
+	updatePresence(raw reflect.Value)
 	buildCoverageData(coverageDataHelper coverageDataHelper)
 	getValues() sets.String
+	coverageState() NodeCoverageState
+	restoreCoverageState(state NodeCoverageState)
 }
 
 // NodeData is the data stored in each node of the resource tree.
@@ -44,6 +50,9 @@ type NodeData struct {
 	FieldType reflect.Type
 	// Path in the resource tree reaching this node.
 	NodePath string
+	// Path to this node in the JSON encoding of the resource, eg: spec.containers[].image.
+	// Pointers and inlined structs share the path of their parent, and the root's path is "".
+	JSONPath string
 	// Link back to parent.
 	Parent NodeInterface
 	// Child nodes are keyed using field names(nodeData.field).
@@ -52,6 +61,24 @@ type NodeData struct {
 	// which gets used later in value-evaluation
 	LeafNode bool
 	Covered  bool
+	// Tests that covered this node, see RequestInfo.Test
+	Tests sets.String
+	// Operations that covered this node, see RequestInfo.Operation
+	Operations sets.String
+	// Mutated is whether an update changed the value of this node, see RequestInfo.OldObject
+	Mutated bool
+	// Present is whether a request explicitly set this node, even to its zero value, see RequestInfo.RawObject
+	Present bool
+	// Expected is the values this node is expected to take, eg: the well-known keys of a map, or nil if unknown.
+	// Nodes that have taken only some of them are partially covered.
+	Expected sets.String
+	// Hits is the number of times this node was covered, eg: once per element of an array, per request.
+	Hits int
+	// FirstSeen and LastSeen are the times of the earliest and latest requests that covered this node, see RequestInfo.Time
+	FirstSeen time.Time
+	LastSeen  time.Time
+	// Exemplars are up to coveragecalculator.MaxExemplars of the objects that covered this node, see RequestInfo.Name
+	Exemplars []coveragecalculator.Exemplar
 }
 
 func (nd *NodeData) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
@@ -60,10 +87,128 @@ func (nd *NodeData) initialize(field string, parent NodeInterface, t reflect.Typ
 	nd.Parent = parent
 	nd.FieldType = t
 	nd.Children = make(map[string]NodeInterface)
+	nd.Tests = sets.String{}
+	nd.Operations = sets.String{}
 
 	if parent != nil {
 		nd.NodePath = parent.GetData().NodePath + "." + field
+		nd.JSONPath = childJSONPath(parent, field)
 	} else {
 		nd.NodePath = field
 	}
 }
+
+// coverageState returns the coverage recorded for the node.
+func (nd *NodeData) coverageState() NodeCoverageState {
+	state := NodeCoverageState{Covered: nd.Covered, Mutated: nd.Mutated, Present: nd.Present, Hits: nd.Hits}
+	if !nd.FirstSeen.IsZero() {
+		state.FirstSeen = &nd.FirstSeen
+		state.LastSeen = &nd.LastSeen
+	}
+	if len(nd.Exemplars) != 0 {
+		state.Exemplars = append([]coveragecalculator.Exemplar{}, nd.Exemplars...)
+	}
+	if nd.Tests.Len() != 0 {
+		state.Tests = nd.Tests.List()
+	}
+	if nd.Operations.Len() != 0 {
+		state.Operations = nd.Operations.List()
+	}
+	return state
+}
+
+// restoreCoverageState merges previously recorded coverage into the node.
+func (nd *NodeData) restoreCoverageState(state NodeCoverageState) {
+	nd.Covered = nd.Covered || state.Covered
+	nd.Mutated = nd.Mutated || state.Mutated
+	nd.Present = nd.Present || state.Present
+	nd.Tests.Insert(state.Tests...)
+	nd.Operations.Insert(state.Operations...)
+	nd.Hits += state.Hits
+	if state.FirstSeen != nil && state.LastSeen != nil {
+		nd.markSeen(*state.FirstSeen)
+		nd.markSeen(*state.LastSeen)
+	}
+	for _, exemplar := range state.Exemplars {
+		nd.Exemplars = coveragecalculator.AddExemplar(nd.Exemplars, exemplar)
+	}
+}
+
+// markCovered marks the node as covered by the request described in updateHelper.
+func (nd *NodeData) markCovered(updateHelper updateCoverageHelper) {
+	nd.Covered = true
+	if len(updateHelper.request.Test) != 0 {
+		nd.Tests.Insert(updateHelper.request.Test)
+	}
+	if len(updateHelper.request.Operation) != 0 {
+		nd.Operations.Insert(updateHelper.request.Operation)
+	}
+	nd.Hits++
+	nd.markSeen(updateHelper.request.Time)
+	if request := updateHelper.request; len(request.Name) != 0 || len(request.UID) != 0 {
+		nd.Exemplars = coveragecalculator.AddExemplar(nd.Exemplars, coveragecalculator.Exemplar{
+			Namespace: request.Namespace,
+			Name:      request.Name,
+			UID:       request.UID,
+			Operation: request.Operation,
+			Time:      request.Time,
+		})
+	}
+}
+
+// markSeen widens the node's FirstSeen and LastSeen to include t. Requests
+// aren't necessarily recorded in the order they were sent, eg: audit events
+// are sent in batches.
+func (nd *NodeData) markSeen(t time.Time) {
+	if nd.FirstSeen.IsZero() || t.Before(nd.FirstSeen) {
+		nd.FirstSeen = t
+	}
+	if t.After(nd.LastSeen) {
+		nd.LastSeen = t
+	}
+}
+
+// valueHits counts how many times each of a node's values was seen, for nodes
+// of enum-like types.
+type valueHits map[string]int
+
+// coverageState records the values seen, and how many times, in state.
+func (h valueHits) coverageState(state *NodeCoverageState) {
+	if len(h) == 0 {
+		return
+	}
+	state.Values = sets.StringKeySet(h).List()
+	// Copied, as the state is used outside the forest's lock
+	state.ValueHits = make(map[string]int, len(h))
+	for value, hits := range h {
+		state.ValueHits[value] = hits
+	}
+}
+
+// restoreCoverageState merges the values recorded in state into h.
+func (h valueHits) restoreCoverageState(state NodeCoverageState) {
+	// Checkpoints taken before values were counted only have Values
+	for _, value := range state.Values {
+		if _, ok := h[value]; !ok {
+			h[value] = 0
+		}
+	}
+	for value, hits := range state.ValueHits {
+		h[value] += hits
+	}
+}
+
+// valueHitsNode is implemented by nodes that count how many times each of
+// their values was seen.
+type valueHitsNode interface {
+	getValueHits() map[string]int
+}
+
+// getValueHits returns how many times each of a node's values was seen, or
+// nil if the node doesn't count them.
+func getValueHits(node NodeInterface) map[string]int {
+	if n, ok := node.(valueHitsNode); ok {
+		return n.getValueHits()
+	}
+	return nil
+}
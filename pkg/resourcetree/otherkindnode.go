@@ -24,7 +24,7 @@ import (
 
 var _ NodeInterface = &OtherKindNode{}
 
-// OtherKindNode represents nodes in the resource tree of types like maps, interfaces, etc
+// OtherKindNode represents nodes in the resource tree of types like interfaces, etc
 type OtherKindNode struct {
 	NodeData
 }
@@ -41,12 +41,24 @@ func (o *OtherKindNode) initialize(field string, parent NodeInterface, t reflect
 
 func (o *OtherKindNode) buildChildNodes(t reflect.Type) {}
 
-func (o *OtherKindNode) updateCoverage(v reflect.Value) {
+func (o *OtherKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	if !v.IsNil() {
-		o.Covered = true
+		o.markCovered(updateHelper)
 	}
 }
 
+func (o *OtherKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	if valuesEqual(v, old) {
+		return false
+	}
+	o.markMutated()
+	return true
+}
+
+func (o *OtherKindNode) updatePresence(raw reflect.Value) {
+	o.markPresent()
+}
+
 // no-op as the coverage is calculated as field coverage in parent node.
 func (o *OtherKindNode) buildCoverageData(coverageHelper coverageDataHelper) {}
 
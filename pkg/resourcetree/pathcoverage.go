@@ -0,0 +1,110 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+)
+
+// pathcoverage.go contains helpers to outline coverage of a resource tree per
+// path from its root, eg: Pod.spec.containers[].livenessProbe, rather than
+// per type. Coverage of a type outlined by BuildCoverageData is merged
+// across every path it occurs at (ConnectedNodes), so eg: the Handler of a
+// livenessProbe can't be told apart from that of a readinessProbe.
+
+// BuildPathCoverageData calculates the coverage information for a resource tree
+// like BuildCoverageData, except that each struct is outlined under its path
+// from the root, with the coverage of that path only. TypeCoverage.Type is the path.
+// It is safe to call concurrently with UpdateCoverage on any tree of the forest.
+func (r *ResourceTree) BuildPathCoverageData(nodeRules NodeRules, fieldRules FieldRules, ignoredFields coveragecalculator.IgnoredFields, options CoverageOptions) []coveragecalculator.TypeCoverage {
+	r.Forest.lock.RLock()
+	defer r.Forest.lock.RUnlock()
+
+	coverageHelper := coverageDataHelper{
+		nodeRules:     nodeRules,
+		fieldRules:    fieldRules,
+		typeCoverage:  &[]coveragecalculator.TypeCoverage{},
+		ignoredFields: ignoredFields,
+		options:       options,
+	}
+	if options.isFiltered() && !coverageHelper.outlined(r.Root.GetData()) {
+		return *coverageHelper.typeCoverage
+	}
+	buildPathCoverageData(r.Root, coverageHelper)
+	return *coverageHelper.typeCoverage
+}
+
+// buildPathCoverageData outlines coverage of node's path if it is a struct,
+// and of the paths below it.
+func buildPathCoverageData(node NodeInterface, coverageHelper coverageDataHelper) {
+	switch n := node.(type) {
+	case *StructKindNode:
+		if !n.LeafNode && len(n.Children) != 0 {
+			buildPathTypeCoverage(n, n.FieldType.PkgPath(), n.FieldType.Name(), coverageHelper)
+		}
+	case *SchemaNode:
+		switch n.kind {
+		case schemaObject:
+			buildPathTypeCoverage(n, n.packageName, n.typeName, coverageHelper)
+		case schemaArray, schemaMap:
+			buildPathChildCoverage(n, coverageHelper)
+		}
+	case *PtrKindNode, *ArrayKindNode, *MapKindNode:
+		// Outlined as part of the path of their parent
+		buildPathChildCoverage(n, coverageHelper)
+	}
+}
+
+// buildPathChildCoverage outlines coverage of the paths below node.
+func buildPathChildCoverage(node NodeInterface, coverageHelper coverageDataHelper) {
+	for _, child := range node.GetData().Children {
+		buildPathCoverageData(child, coverageHelper)
+	}
+}
+
+// buildPathTypeCoverage outlines coverage of the fields of a struct at node's
+// path, where the struct is of the given type, and of the paths below it.
+func buildPathTypeCoverage(node NodeInterface, packageName string, typeName string, coverageHelper coverageDataHelper) {
+	coverage := coveragecalculator.TypeCoverage{
+		Type:    jsonPathName(node.GetData()),
+		Package: packageName,
+		Fields:  make(map[string]*coveragecalculator.FieldCoverage),
+	}
+	fields := jsonFields(node)
+	for field, child := range fields {
+		if !coverageHelper.fieldRules.Apply(field) {
+			continue
+		}
+		coverage.Fields[field] = &coveragecalculator.FieldCoverage{
+			Field:      field,
+			Ignored:    coverageHelper.fieldIgnored(packageName, typeName, field, child),
+			Values:     sets.String{},
+			Tests:      sets.String{},
+			Operations: sets.String{},
+		}
+		coverageHelper.mergeFieldCoverage(coverage.Fields[field], child)
+	}
+	*coverageHelper.typeCoverage = append(*coverageHelper.typeCoverage, coverage)
+
+	for field := range coverage.Fields {
+		child := fields[field]
+		if !coverage.Fields[field].Ignored && coverageHelper.outlined(child.GetData()) && coverageHelper.nodeRules.Apply(child) {
+			buildPathCoverageData(child, coverageHelper)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+// presence.go contains helpers to tell which fields of a resource a request
+// explicitly set, by walking the JSON it was sent as alongside the tree.
+// Unlike coverage, presence counts fields set to their zero value, eg: false.
+
+import (
+	"reflect"
+)
+
+// markPresent marks the node as present in a request.
+func (nd *NodeData) markPresent() {
+	nd.Present = true
+}
+
+// rawElem returns the value of an unstructured JSON value, or an invalid
+// value if it is null.
+func rawElem(raw reflect.Value) reflect.Value {
+	if raw.Kind() == reflect.Interface {
+		return raw.Elem()
+	}
+	return raw
+}
@@ -49,21 +49,46 @@ func (p *PtrKindNode) buildChildNodes(t reflect.Type) {
 	childNode := p.Tree.createNode(childName, p, t.Elem())
 	p.Children[childName] = childNode
 	childNode.buildChildNodes(t.Elem())
+	// A pointer to an enum is expected to take the values of the enum
+	p.Expected = childNode.GetData().Expected
 }
 
-func (p *PtrKindNode) updateCoverage(v reflect.Value) {
+func (p *PtrKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	if !v.IsNil() {
-		p.Covered = true
-		p.Children[p.Field+ptrNodeNameSuffix].updateCoverage(v.Elem())
+		p.markCovered(updateHelper)
+		p.Children[p.Field+ptrNodeNameSuffix].updateCoverage(v.Elem(), updateHelper)
 	}
 }
 
+func (p *PtrKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	// Setting or unsetting a pointer mutates it, even to the zero value
+	mutated := v.IsNil() != old.IsNil()
+	if p.Children[p.Field+ptrNodeNameSuffix].updateMutation(elemOrZero(v), elemOrZero(old)) {
+		mutated = true
+	}
+	if mutated {
+		p.markMutated()
+	}
+	return mutated
+}
+
+func (p *PtrKindNode) updatePresence(raw reflect.Value) {
+	p.markPresent()
+	p.Children[p.Field+ptrNodeNameSuffix].updatePresence(raw)
+}
+
 func (p *PtrKindNode) buildCoverageData(coverageHelper coverageDataHelper) {
 	if p.objKind == reflect.Struct {
 		p.Children[p.Field+ptrNodeNameSuffix].buildCoverageData(coverageHelper)
 	}
 }
 
+// getValues returns the values of the element, as a pointer to an enum is
+// covered by the values it points to.
 func (p *PtrKindNode) getValues() sets.String {
-	return nil
+	return p.Children[p.Field+ptrNodeNameSuffix].getValues()
+}
+
+func (p *PtrKindNode) getValueHits() map[string]int {
+	return getValueHits(p.Children[p.Field+ptrNodeNameSuffix])
 }
@@ -0,0 +1,49 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var quantityType = reflect.TypeOf(resource.Quantity{})
+
+var _ NodeInterface = &QuantityTypeNode{}
+
+// QuantityTypeNode is a node type that encapsulates resource.Quantity fields,
+// e.g. the values of a corev1.ResourceList. Quantities are structs of
+// unexported fields, but their values are specified as strings, so we create
+// QuantityTypeNodes and mark them as leafnodes. The format a quantity was
+// specified in, e.g. BinarySI for 1Gi or DecimalSI for 1G, is recorded as
+// its value.
+type QuantityTypeNode struct {
+	valuesLeafNode // Formats seen for this node.
+}
+
+func (q *QuantityTypeNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if !v.CanInterface() {
+		return
+	}
+	quantity := v.Interface().(resource.Quantity)
+	// Like other numbers, let's not assume coverage of 0
+	if !quantity.IsZero() {
+		q.values.Insert(string(quantity.Format))
+		q.markCovered(updateHelper)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"encoding/json"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var rawExtensionType = reflect.TypeOf(runtime.RawExtension{})
+
+var _ NodeInterface = &RawExtensionTypeNode{}
+
+// RawExtensionTypeNode is a node type that encapsulates runtime.RawExtension
+// fields, e.g. appsv1.ControllerRevision.Data, which embed an object of any
+// type as JSON. The type isn't known until a value is seen, so we create
+// RawExtensionTypeNodes and mark them as leafnodes. The apiVersion/kind of
+// embedded objects is recorded as its value, and objects of a kind that has
+// a tree in the forest, see ResourceForest.RawExtensionKey, are decoded into
+// that tree.
+type RawExtensionTypeNode struct {
+	valuesLeafNode // apiVersion/kind of the objects seen for this node.
+}
+
+func (r *RawExtensionTypeNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if !v.CanInterface() {
+		return
+	}
+	extension := v.Interface().(runtime.RawExtension)
+	raw := extension.Raw
+	if len(raw) == 0 && extension.Object != nil {
+		var err error
+		if raw, err = json.Marshal(extension.Object); err != nil {
+			return
+		}
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return
+	}
+	r.markCovered(updateHelper)
+	r.updateEmbeddedCoverage(raw, updateHelper)
+}
+
+// updateEmbeddedCoverage updates coverage data in the tree of the object
+// embedded as raw, if it has an apiVersion and kind, and the forest a tree for it.
+func (r *RawExtensionTypeNode) updateEmbeddedCoverage(raw []byte, updateHelper updateCoverageHelper) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil || len(typeMeta.Kind) == 0 {
+		return
+	}
+	r.values.Insert(typeMeta.APIVersion + "/" + typeMeta.Kind)
+
+	forest := r.Tree.Forest
+	if forest.RawExtensionKey == nil {
+		return
+	}
+	tree, ok := forest.TopLevelTrees[forest.RawExtensionKey(typeMeta.GroupVersionKind())]
+	if !ok {
+		return
+	}
+	var unstructured interface{}
+	if err := json.Unmarshal(raw, &unstructured); err != nil {
+		return
+	}
+	// Schema trees are updated from unstructured values
+	v := reflect.ValueOf(unstructured)
+	if t := tree.Root.GetData().FieldType; t != nil {
+		object := reflect.New(t)
+		if err := json.Unmarshal(raw, object.Interface()); err != nil {
+			return
+		}
+		v = object.Elem()
+	}
+	// The forest is already locked by the update of this node's tree
+	tree.Root.updateCoverage(v, updateHelper)
+	tree.Root.updatePresence(reflect.ValueOf(unstructured))
+}
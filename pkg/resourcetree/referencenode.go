@@ -0,0 +1,90 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+var _ NodeInterface = &ReferenceNode{}
+
+// ReferenceNode represents nodes in the resource tree of a struct type that an
+// ancestor node is already expanding, eg: the items of an apiextensions
+// JSONSchemaProps, which are JSONSchemaProps themselves. Expanding the type
+// again would never end, so the node refers to the ancestor, Target, instead,
+// and coverage of its values is recorded in Target's subtree.
+type ReferenceNode struct {
+	NodeData
+	Target NodeInterface
+}
+
+// GetData returns node data
+func (r *ReferenceNode) GetData() NodeData {
+	return r.NodeData
+}
+
+func (r *ReferenceNode) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
+	r.NodeData.initialize(field, parent, t, rt)
+	r.LeafNode = true
+}
+
+func (r *ReferenceNode) buildChildNodes(t reflect.Type) {}
+
+// ancestorOfType returns the closest struct node of type t from node up, or nil.
+func ancestorOfType(node NodeInterface, t reflect.Type) NodeInterface {
+	for ; node != nil; node = node.GetData().Parent {
+		if _, ok := node.(*StructKindNode); ok && node.GetData().FieldType == t {
+			return node
+		}
+	}
+	return nil
+}
+
+func (r *ReferenceNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if v.IsValid() {
+		r.markCovered(updateHelper)
+		r.Target.updateCoverage(v, updateHelper)
+	}
+}
+
+func (r *ReferenceNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	// Nil pointers and missing elements on either side are compared as zero
+	// values, whose own references would be compared again and again, so
+	// only descend into values that differ
+	if valuesEqual(v, old) || !r.Target.updateMutation(v, old) {
+		return false
+	}
+	r.markMutated()
+	return true
+}
+
+func (r *ReferenceNode) updatePresence(raw reflect.Value) {
+	r.markPresent()
+	r.Target.updatePresence(raw)
+}
+
+func (r *ReferenceNode) buildCoverageData(coverageHelper coverageDataHelper) {
+	// Target outlines coverage of the type, including that of values of this
+	// node, unless it already has
+	r.Target.buildCoverageData(coverageHelper)
+}
+
+func (r *ReferenceNode) getValues() sets.String {
+	return nil
+}
@@ -19,7 +19,10 @@ package resourcetree
 import (
 	"container/list"
 	"reflect"
+	"sync"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 )
@@ -28,26 +31,68 @@ import (
 // for top-level resource types and all connected nodes across resource trees.
 type ResourceForest struct {
 	Version string
-	// Key is ResourceTree.ResourceName
+	// Key is the key passed to AddResourceTree, eg: the resource's GroupVersionKind,
+	// as ResourceTree.ResourceName need not be unique across groups and versions
 	TopLevelTrees map[string]ResourceTree
 	// Head of the linked list keyed by nodeData.fieldType.pkg + nodeData.fieldType.Name()
 	ConnectedNodes map[string]*list.List
+	// ExpectedMapKeys are the well-known keys of maps, keyed by the field holding the map,
+	// eg: k8s.io/api/core/v1.ResourceRequirements.limits, or by a named map type,
+	// eg: k8s.io/api/core/v1.ResourceList. Maps that have had only some of them are
+	// partially covered. Trees are expected to be added after it is set.
+	ExpectedMapKeys map[string][]string
+	// RawExtensionKey returns the key of the tree that objects of a kind embedded
+	// in a runtime.RawExtension are recorded in, eg: as passed to AddResourceTree.
+	// If nil, embedded objects are not recorded.
+	RawExtensionKey func(gvk schema.GroupVersionKind) string
+
+	// lock guards the coverage data of every node in the forest, as coverage
+	// of one tree is built from nodes that span all trees (ConnectedNodes).
+	// Trees are expected to be added before coverage is updated or built.
+	lock sync.RWMutex
 }
 
-// AddResourceTree adds a resource tree to the resource forest.
-func (r *ResourceForest) AddResourceTree(resourceName string, resourceType reflect.Type) {
+// AddResourceTree adds a resource tree, whose root node is named resourceName,
+// to the resource forest under the given key.
+func (r *ResourceForest) AddResourceTree(key string, resourceName string, resourceType reflect.Type) {
 	tree := ResourceTree{
 		ResourceName: resourceName,
 		Forest:       r,
 	}
 	tree.BuildResourceTree(resourceType)
-	r.TopLevelTrees[resourceName] = tree
+	r.TopLevelTrees[key] = tree
+}
+
+// AddSchemaResourceTree adds a resource tree built from an OpenAPI v3 schema,
+// whose root node is named resourceName, to the resource forest under the given key.
+// Coverage of the tree is updated from unstructured values, eg: reflect.ValueOf
+// an interface{} that JSON was decoded into.
+func (r *ResourceForest) AddSchemaResourceTree(key string, resourceName string, packageName string, schema *apiextensionsv1.JSONSchemaProps) {
+	tree := ResourceTree{
+		ResourceName: resourceName,
+		Forest:       r,
+	}
+	tree.BuildSchemaResourceTree(packageName, schema)
+	r.TopLevelTrees[key] = tree
+}
+
+// Tests returns the names of all tests that coverage has been recorded for.
+func (r *ResourceForest) Tests() sets.String {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	tests := sets.String{}
+	for _, tree := range r.TopLevelTrees {
+		// The root is covered by every update of the tree
+		tests = tests.Union(tree.Root.GetData().Tests)
+	}
+	return tests
 }
 
 // getConnectedNodeCoverage calculates the outlined coverage for a Type using ConnectedNodes linkedlist.
 // We traverse through each element in the linkedlist and merge
 // coverage data into a single coveragecalculator.TypeCoverage object.
-func (r *ResourceForest) getConnectedNodeCoverage(fieldType reflect.Type, fieldRules FieldRules, ignoredFields coveragecalculator.IgnoredFields) coveragecalculator.TypeCoverage {
+func (r *ResourceForest) getConnectedNodeCoverage(fieldType reflect.Type, coverageHelper coverageDataHelper) coveragecalculator.TypeCoverage {
 	packageName := fieldType.PkgPath()
 	coverage := coveragecalculator.TypeCoverage{
 		Type:    fieldType.Name(),
@@ -58,17 +103,19 @@ func (r *ResourceForest) getConnectedNodeCoverage(fieldType reflect.Type, fieldR
 	if value, ok := r.ConnectedNodes[fieldType.PkgPath()+"."+fieldType.Name()]; ok {
 		for elem := value.Front(); elem != nil; elem = elem.Next() {
 			node := elem.Value.(NodeInterface)
-			for field, v := range node.GetData().Children {
-				if fieldRules.Apply(field) {
+			for field, v := range jsonFields(node) {
+				if coverageHelper.fieldRules.Apply(field) {
 					if _, ok := coverage.Fields[field]; !ok {
 						coverage.Fields[field] = &coveragecalculator.FieldCoverage{
-							Field:   field,
-							Ignored: ignoredFields.FieldIgnored(packageName, fieldType.Name(), field),
-							Values:  sets.String{},
+							Field:      field,
+							Ignored:    coverageHelper.fieldIgnored(packageName, fieldType.Name(), field, v),
+							Values:     sets.String{},
+							Tests:      sets.String{},
+							Operations: sets.String{},
 						}
 					}
 					// merge values across the list.
-					coverage.Fields[field].Merge(v.GetData().Covered, v.getValues())
+					coverageHelper.mergeFieldCoverage(coverage.Fields[field], v)
 				}
 			}
 		}
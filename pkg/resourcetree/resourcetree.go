@@ -19,7 +19,9 @@ package resourcetree
 import (
 	"container/list"
 	"reflect"
+	"time"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 )
@@ -31,6 +33,52 @@ type ResourceTree struct {
 	Forest       *ResourceForest
 }
 
+// RequestInfo describes the request a resource value was taken from, so that
+// coverage can be attributed back to it.
+type RequestInfo struct {
+	// Test is the name of the test that sent the request, if known.
+	Test string
+	// Operation is the operation of the request, eg: CREATE or UPDATE, if known.
+	Operation string
+	// OldObject, if valid, is the value an update replaced. Nodes whose value
+	// differs from it are marked Mutated. It must be of the same type as the new value.
+	OldObject reflect.Value
+	// RawObject, if valid, is the unstructured JSON the value was decoded
+	// from. Nodes set in it, even to their zero value, are marked Present.
+	RawObject reflect.Value
+	// Namespace, Name and UID of the object sent, if known. Nodes it covers
+	// keep a few of these as exemplars, see NodeData.Exemplars.
+	Namespace string
+	Name      string
+	UID       string
+	// Time the request was sent, if known. UpdateCoverageFromRequest defaults
+	// it to the time coverage is recorded.
+	Time time.Time
+}
+
+// CoverageOptions controls which of the recorded coverage BuildCoverageData considers.
+type CoverageOptions struct {
+	// Test, if set, only considers nodes covered by the named test. Values
+	// seen for a field are not attributed to tests, and are not filtered.
+	Test string
+	// Operation, if set, only considers nodes covered by requests of the
+	// named operation, eg: UPDATE. Values are not filtered either.
+	Operation string
+	// Mutated, if set, considers nodes whose value was changed by an update
+	// instead of nodes that were covered, so that fields which were only sent
+	// back unchanged don't count.
+	Mutated bool
+	// AllTypes, if set, outlines coverage of every type reachable from the
+	// resource, instead of only the types of covered fields, so that the
+	// fields counted don't grow as more of the resource is covered.
+	AllTypes bool
+}
+
+// isFiltered returns whether the options consider less than all recorded coverage.
+func (o CoverageOptions) isFiltered() bool {
+	return len(o.Test) != 0 || len(o.Operation) != 0 || o.Mutated
+}
+
 // coverageDataHelper is a encapsulator parameter type to the BuildCoverageData method
 // so as to avoid long parameter list.
 type coverageDataHelper struct {
@@ -39,23 +87,89 @@ type coverageDataHelper struct {
 	fieldRules    FieldRules
 	ignoredFields coveragecalculator.IgnoredFields
 	coveredTypes  sets.String
+	options       CoverageOptions
+}
+
+// covered returns whether a node counts as covered under the helper's CoverageOptions.
+func (c *coverageDataHelper) covered(nd NodeData) bool {
+	if c.options.Mutated && !nd.Mutated {
+		return false
+	} else if !c.options.Mutated && !nd.Covered {
+		return false
+	}
+	if len(c.options.Operation) != 0 && !nd.Operations.Has(c.options.Operation) {
+		return false
+	}
+	return len(c.options.Test) == 0 || nd.Tests.Has(c.options.Test)
+}
+
+// outlined returns whether coverage of a node's type is outlined under the
+// helper's CoverageOptions.
+func (c *coverageDataHelper) outlined(nd NodeData) bool {
+	return c.options.AllTypes || c.covered(nd)
+}
+
+// mergeFieldCoverage merges the coverage recorded for a node into that of the
+// field it is the value of.
+func (c *coverageDataHelper) mergeFieldCoverage(f *coveragecalculator.FieldCoverage, node NodeInterface) {
+	data := node.GetData()
+	covered := c.covered(data)
+	f.Merge(covered, node.getValues(), data.Tests, data.Operations)
+	f.MergeMutated(data.Mutated)
+	f.MergePresent(data.Present)
+	f.MergeExpected(data.Expected)
+	f.MergeHits(covered, data.Hits, data.FirstSeen, data.LastSeen, getValueHits(node))
+	f.MergeExemplars(covered, data.Exemplars)
+}
+
+// fieldIgnored returns whether a field of a type is marked ignored, by its
+// JSON name or, for ignore files written before reports used JSON names, by
+// the Go name of the field's node.
+func (c *coverageDataHelper) fieldIgnored(packageName string, typeName string, field string, node NodeInterface) bool {
+	if c.ignoredFields.FieldIgnored(packageName, typeName, field) || c.ignoredFields.FieldIgnored(packageName, typeName, node.GetData().Field) {
+		return true
+	}
+	// Fields of inlined structs can also be ignored as fields of the type
+	// declaring them, eg: VolumeSource.hostPath for Volume.hostPath
+	declaringType := node.GetData().Parent.GetData().FieldType
+	if declaringType == nil || declaringType.Name() == typeName {
+		return false
+	}
+	return c.fieldIgnored(declaringType.PkgPath(), declaringType.Name(), field, node)
+}
+
+// updateCoverageHelper is a encapsulator parameter type to the updateCoverage
+// method, carrying what is common to every node updated for a request.
+type updateCoverageHelper struct {
+	request RequestInfo
 }
 
 func (r *ResourceTree) createNode(field string, parent NodeInterface, t reflect.Type) NodeInterface {
+	// Recursive types, eg: a struct with a slice of itself, would be expanded forever
+	if t.Kind() == reflect.Struct {
+		if ancestor := ancestorOfType(parent, t); ancestor != nil {
+			n := &ReferenceNode{Target: ancestor}
+			n.initialize(field, parent, t, r)
+			return n
+		}
+	}
+
 	var n NodeInterface
 	switch t.Kind() {
 	case reflect.Struct:
-		n = new(StructKindNode)
+		n = createStructNode(t)
 	case reflect.Array, reflect.Slice:
 		n = new(ArrayKindNode)
 	case reflect.Ptr, reflect.UnsafePointer, reflect.Uintptr:
 		n = new(PtrKindNode)
+	case reflect.Map:
+		n = new(MapKindNode)
 	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
 		reflect.Int, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
 		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		n = new(BasicTypeKindNode)
 	default:
-		n = new(OtherKindNode) // Maps, interfaces, etc
+		n = new(OtherKindNode) // Interfaces, etc
 	}
 
 	n.initialize(field, parent, t, r)
@@ -71,25 +185,80 @@ func (r *ResourceTree) createNode(field string, parent NodeInterface, t reflect.
 	return n
 }
 
+// createStructNode returns a node for a struct type, which is a leaf node for
+// types whose values are encoded in JSON other than as objects.
+func createStructNode(t reflect.Type) NodeInterface {
+	switch t {
+	case quantityType:
+		return new(QuantityTypeNode)
+	case intOrStringType:
+		return new(IntOrStringTypeNode)
+	case rawExtensionType:
+		return new(RawExtensionTypeNode)
+	}
+	return new(StructKindNode)
+}
+
 // BuildResourceTree builds a resource tree by calling into analyzeType method starting from root.
 func (r *ResourceTree) BuildResourceTree(t reflect.Type) {
 	r.Root = r.createNode(r.ResourceName, nil, t)
 	r.Root.buildChildNodes(t)
 }
 
+// BuildSchemaResourceTree builds a resource tree of SchemaNodes from an OpenAPI v3 schema,
+// outlining coverage of the objects it describes under packageName.
+func (r *ResourceTree) BuildSchemaResourceTree(packageName string, schema *apiextensionsv1.JSONSchemaProps) {
+	root := &SchemaNode{
+		Schema:      schema,
+		packageName: packageName,
+		typeName:    r.ResourceName,
+	}
+	root.initialize(r.ResourceName, nil, nil, r)
+	root.buildChildNodes(nil)
+	r.Root = root
+}
+
 // UpdateCoverage updates coverage data in the resource tree based on the provided reflect.Value
 func (r *ResourceTree) UpdateCoverage(v reflect.Value) {
-	r.Root.updateCoverage(v)
+	r.UpdateCoverageFromRequest(v, RequestInfo{})
+}
+
+// UpdateCoverageFromRequest updates coverage data in the resource tree based
+// on the provided reflect.Value, attributing it to the described request.
+// It is safe to call concurrently with BuildCoverageData on any tree of the forest.
+func (r *ResourceTree) UpdateCoverageFromRequest(v reflect.Value, request RequestInfo) {
+	r.Forest.lock.Lock()
+	defer r.Forest.lock.Unlock()
+
+	if request.Time.IsZero() {
+		request.Time = time.Now()
+	}
+	r.Root.updateCoverage(v, updateCoverageHelper{request: request})
+	if request.OldObject.IsValid() {
+		r.Root.updateMutation(v, request.OldObject)
+	}
+	if raw := rawElem(request.RawObject); raw.IsValid() {
+		r.Root.updatePresence(raw)
+	}
 }
 
 // BuildCoverageData calculates the coverage information for a resource tree by applying provided Node and Field rules.
-func (r *ResourceTree) BuildCoverageData(nodeRules NodeRules, fieldRules FieldRules, ignoredFields coveragecalculator.IgnoredFields) []coveragecalculator.TypeCoverage {
+// It is safe to call concurrently with UpdateCoverage on any tree of the forest.
+func (r *ResourceTree) BuildCoverageData(nodeRules NodeRules, fieldRules FieldRules, ignoredFields coveragecalculator.IgnoredFields, options CoverageOptions) []coveragecalculator.TypeCoverage {
+	r.Forest.lock.RLock()
+	defer r.Forest.lock.RUnlock()
+
 	coverageHelper := coverageDataHelper{
 		nodeRules:     nodeRules,
 		fieldRules:    fieldRules,
 		typeCoverage:  &[]coveragecalculator.TypeCoverage{},
 		ignoredFields: ignoredFields,
 		coveredTypes:  sets.String{},
+		options:       options,
+	}
+	// A test, or operation, that never sent this resource has covered nothing in it
+	if options.isFiltered() && !coverageHelper.outlined(r.Root.GetData()) {
+		return *coverageHelper.typeCoverage
 	}
 	r.Root.buildCoverageData(coverageHelper)
 	return *coverageHelper.typeCoverage
@@ -0,0 +1,320 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+)
+
+const (
+	mapNodeNameSuffix = "-map"
+)
+
+// schemaNodeKind is the shape of the values a SchemaNode's schema describes.
+type schemaNodeKind int
+
+const (
+	schemaLeaf   schemaNodeKind = iota
+	schemaObject                // object with properties
+	schemaArray                 // array with an items schema
+	schemaMap                   // object with an additionalProperties schema
+)
+
+var _ NodeInterface = &SchemaNode{}
+
+// SchemaNode represents resource tree nodes built from an OpenAPI v3 schema,
+// eg: that of a CustomResourceDefinition, instead of from a reflect.Type.
+// Values are expected to be unstructured, ie: decoded from JSON into an interface{}.
+type SchemaNode struct {
+	NodeData
+	Schema *apiextensionsv1.JSONSchemaProps
+	// Package and Type that coverage of objects is outlined under. Schemas
+	// have no type names, so these are the resource's apiVersion and the
+	// path to the object from the resource, eg: Foo.spec.containers[].
+	packageName  string
+	typeName     string
+	kind         schemaNodeKind
+	values       valueHits // Values seen for this node, and how many times. Useful for enum types.
+	possibleEnum bool      // Flag to indicate if this is a possible enum.
+}
+
+// GetData returns node data
+func (s *SchemaNode) GetData() NodeData {
+	return s.NodeData
+}
+
+func (s *SchemaNode) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
+	s.NodeData.initialize(field, parent, t, rt)
+	s.values = valueHits{}
+}
+
+// buildChildNodes builds child nodes from s.Schema, as there is no reflect.Type to build them from.
+func (s *SchemaNode) buildChildNodes(t reflect.Type) {
+	switch {
+	case len(s.Schema.Properties) != 0:
+		s.kind = schemaObject
+		for field := range s.Schema.Properties {
+			property := s.Schema.Properties[field]
+			s.addChild(field, s.typeName+"."+field, &property)
+		}
+	case s.Schema.Items != nil && s.Schema.Items.Schema != nil:
+		s.kind = schemaArray
+		s.addChild(s.Field+arrayNodeNameSuffix, s.typeName+"[]", s.Schema.Items.Schema)
+	case s.Schema.AdditionalProperties != nil && s.Schema.AdditionalProperties.Schema != nil:
+		s.kind = schemaMap
+		s.addChild(s.Field+mapNodeNameSuffix, s.typeName+"{}", s.Schema.AdditionalProperties.Schema)
+	default:
+		s.kind = schemaLeaf
+		s.LeafNode = true
+		// Treating booleans as possible enums to support tighter coverage information.
+		s.possibleEnum = len(s.Schema.Enum) != 0 || s.Schema.Type == "boolean"
+		if len(s.Schema.Enum) != 0 {
+			s.Expected = schemaEnumValues(s.Schema.Enum)
+		}
+	}
+}
+
+// schemaEnumValues returns the values of a schema's enum, as schemaValueString
+// returns them.
+func schemaEnumValues(enum []apiextensionsv1.JSON) sets.String {
+	values := sets.String{}
+	for _, value := range enum {
+		var v interface{}
+		if err := json.Unmarshal(value.Raw, &v); err == nil {
+			values.Insert(schemaValueString(reflect.ValueOf(v)))
+		}
+	}
+	return values
+}
+
+func (s *SchemaNode) addChild(field string, typeName string, schema *apiextensionsv1.JSONSchemaProps) {
+	childNode := &SchemaNode{
+		Schema:      schema,
+		packageName: s.packageName,
+		typeName:    typeName,
+	}
+	childNode.initialize(field, s, nil, s.Tree)
+	s.Children[field] = childNode
+	childNode.buildChildNodes(nil)
+}
+
+func (s *SchemaNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	// Absent, null, or not of the type described by the schema
+	if !v.IsValid() {
+		return
+	}
+
+	switch s.kind {
+	case schemaObject:
+		if v.Kind() != reflect.Map {
+			return
+		}
+		s.markCovered(updateHelper)
+		for field, child := range s.Children {
+			child.updateCoverage(v.MapIndex(reflect.ValueOf(field)), updateHelper)
+		}
+	case schemaArray:
+		if v.Kind() != reflect.Slice {
+			return
+		}
+		s.markCovered(updateHelper)
+		for i := 0; i < v.Len(); i++ {
+			s.Children[s.Field+arrayNodeNameSuffix].updateCoverage(v.Index(i), updateHelper)
+		}
+	case schemaMap:
+		if v.Kind() != reflect.Map || v.Len() == 0 {
+			return
+		}
+		s.markCovered(updateHelper)
+		for _, key := range v.MapKeys() {
+			s.Children[s.Field+mapNodeNameSuffix].updateCoverage(v.MapIndex(key), updateHelper)
+		}
+	default:
+		value := schemaValueString(v)
+		// There are some enums that use "" as an explicit value ...
+		if s.possibleEnum {
+			s.values[value]++
+		}
+		// ... but let's not assume coverage until a non-empty value is added
+		if len(value) > 0 {
+			s.markCovered(updateHelper)
+		}
+	}
+}
+
+func (s *SchemaNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if old.Kind() == reflect.Interface {
+		old = old.Elem()
+	}
+
+	mutated := false
+	switch {
+	case s.kind == schemaObject && v.Kind() == reflect.Map && old.Kind() == reflect.Map:
+		for field, child := range s.Children {
+			if child.updateMutation(v.MapIndex(reflect.ValueOf(field)), old.MapIndex(reflect.ValueOf(field))) {
+				mutated = true
+			}
+		}
+	case s.kind == schemaArray && v.Kind() == reflect.Slice && old.Kind() == reflect.Slice:
+		// Elements are compared by index, so reordering mutates them
+		mutated = v.Len() != old.Len()
+		for i := 0; i < v.Len() && i < old.Len(); i++ {
+			if s.Children[s.Field+arrayNodeNameSuffix].updateMutation(v.Index(i), old.Index(i)) {
+				mutated = true
+			}
+		}
+	case s.kind == schemaMap && v.Kind() == reflect.Map && old.Kind() == reflect.Map:
+		mutated = v.Len() != old.Len()
+		for _, key := range v.MapKeys() {
+			if s.Children[s.Field+mapNodeNameSuffix].updateMutation(v.MapIndex(key), old.MapIndex(key)) {
+				mutated = true
+			}
+		}
+	default:
+		// Leaves, and values added, removed or not of the type described by the schema
+		mutated = !valuesEqual(v, old)
+	}
+	if mutated {
+		s.markMutated()
+	}
+	return mutated
+}
+
+func (s *SchemaNode) updatePresence(raw reflect.Value) {
+	s.markPresent()
+	switch {
+	case s.kind == schemaObject && raw.Kind() == reflect.Map:
+		for field, child := range s.Children {
+			if value := rawElem(raw.MapIndex(reflect.ValueOf(field))); value.IsValid() {
+				child.updatePresence(value)
+			}
+		}
+	case s.kind == schemaArray && raw.Kind() == reflect.Slice:
+		for i := 0; i < raw.Len(); i++ {
+			if value := rawElem(raw.Index(i)); value.IsValid() {
+				s.Children[s.Field+arrayNodeNameSuffix].updatePresence(value)
+			}
+		}
+	case s.kind == schemaMap && raw.Kind() == reflect.Map:
+		for _, key := range raw.MapKeys() {
+			if value := rawElem(raw.MapIndex(key)); value.IsValid() {
+				s.Children[s.Field+mapNodeNameSuffix].updatePresence(value)
+			}
+		}
+	}
+}
+
+// schemaValueString returns the string form of an unstructured value, or ""
+// for zero values, to match what BasicTypeKindNode considers covered.
+func schemaValueString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() != 0 {
+			return strconv.FormatInt(v.Int(), 10)
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Float() != 0 {
+			return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+		}
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Map, reflect.Slice:
+		// eg: x-kubernetes-preserve-unknown-fields, or schemas with no type
+		if v.Len() != 0 {
+			return fmt.Sprint(v.Interface())
+		}
+	}
+	return ""
+}
+
+func (s *SchemaNode) buildCoverageData(coverageHelper coverageDataHelper) {
+	switch s.kind {
+	case schemaArray, schemaMap:
+		for _, child := range s.Children {
+			child.buildCoverageData(coverageHelper)
+		}
+	case schemaObject:
+		coverage := coveragecalculator.TypeCoverage{
+			Type:    s.typeName,
+			Package: s.packageName,
+			Fields:  make(map[string]*coveragecalculator.FieldCoverage),
+		}
+		for field, child := range s.Children {
+			if !coverageHelper.fieldRules.Apply(field) {
+				continue
+			}
+			coverage.Fields[field] = &coveragecalculator.FieldCoverage{
+				Field:      field,
+				Ignored:    coverageHelper.fieldIgnored(s.packageName, s.typeName, field, child),
+				Values:     sets.String{},
+				Tests:      sets.String{},
+				Operations: sets.String{},
+			}
+			coverageHelper.mergeFieldCoverage(coverage.Fields[field], child)
+		}
+		*coverageHelper.typeCoverage = append(*coverageHelper.typeCoverage, coverage)
+
+		for field := range coverage.Fields {
+			node := s.Children[field]
+			if !coverage.Fields[field].Ignored && coverageHelper.outlined(node.GetData()) && coverageHelper.nodeRules.Apply(node) {
+				node.buildCoverageData(coverageHelper)
+			}
+		}
+	}
+}
+
+func (s *SchemaNode) getValues() sets.String {
+	if s.possibleEnum {
+		return sets.StringKeySet(s.values)
+	}
+
+	return nil
+}
+
+func (s *SchemaNode) getValueHits() map[string]int {
+	if s.possibleEnum {
+		return s.values
+	}
+
+	return nil
+}
+
+func (s *SchemaNode) coverageState() NodeCoverageState {
+	state := s.NodeData.coverageState()
+	s.values.coverageState(&state)
+	return state
+}
+
+func (s *SchemaNode) restoreCoverageState(state NodeCoverageState) {
+	s.NodeData.restoreCoverageState(state)
+	s.values.restoreCoverageState(state)
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"container/list"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+)
+
+const (
+	schemaTypeName = "SchemaType"
+	schemaPackage  = "example.com/v1"
+)
+
+func getTestSchema() *apiextensionsv1.JSONSchemaProps {
+	return &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"replicas": {Type: "integer"},
+					"paused":   {Type: "boolean"},
+					"mode": {Type: "string", Enum: []apiextensionsv1.JSON{
+						{Raw: []byte(`"Fast"`)}, {Raw: []byte(`"Slow"`)},
+					}},
+					"containers": {
+						Type: "array",
+						Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+							Type: "object",
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"name":  {Type: "string"},
+								"image": {Type: "string"},
+							},
+						}},
+					},
+					"labels": {
+						Type:                 "object",
+						AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &apiextensionsv1.JSONSchemaProps{Type: "string"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func getTestSchemaTree() *ResourceTree {
+	forest := ResourceForest{
+		Version:        "TestVersion",
+		ConnectedNodes: make(map[string]*list.List),
+		TopLevelTrees:  make(map[string]ResourceTree),
+	}
+	forest.AddSchemaResourceTree(schemaTypeName, schemaTypeName, schemaPackage, getTestSchema())
+	tree := forest.TopLevelTrees[schemaTypeName]
+	return &tree
+}
+
+func getTypeCoverage(typeCoverage []coveragecalculator.TypeCoverage, typeName string) *coveragecalculator.TypeCoverage {
+	for i := range typeCoverage {
+		if typeCoverage[i].Type == typeName {
+			return &typeCoverage[i]
+		}
+	}
+	return nil
+}
+
+func TestSchemaResourceTree(t *testing.T) {
+	tree := getTestSchemaTree()
+	var value interface{}
+	if err := json.Unmarshal([]byte(`{"spec": {"replicas": 2, "paused": false, "mode": "Fast", "containers": [{"name": "c"}], "labels": {"a": "b"}}}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(value), RequestInfo{Test: "test-a"})
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	if len(typeCoverage) != 3 {
+		t.Fatalf("Expected 3 types (%s, .spec, .spec.containers[]), found: %d", schemaTypeName, len(typeCoverage))
+	}
+
+	spec := getTypeCoverage(typeCoverage, schemaTypeName+".spec")
+	if spec == nil || spec.Package != schemaPackage {
+		t.Fatalf("Expected coverage of %s.spec in package %s, found: %+v", schemaTypeName, schemaPackage, spec)
+	}
+	for _, field := range []string{"replicas", "paused", "mode", "containers", "labels"} {
+		if !spec.Fields[field].Coverage || !spec.Fields[field].Tests.Has("test-a") {
+			t.Errorf("Expected spec.%s covered by test-a, found: %+v", field, spec.Fields[field])
+		}
+	}
+	if values := spec.Fields["mode"].Values; values.Len() != 1 || !values.Has("Fast") {
+		t.Errorf("Expected values [Fast] for spec.mode, found: %v", values.List())
+	}
+	if hits := spec.Fields["mode"].ValueHits; len(hits) != 1 || hits["Fast"] != 1 {
+		t.Errorf("Expected Fast seen once for spec.mode, found: %v", hits)
+	}
+	if mode := spec.Fields["mode"]; mode.Expected.Len() != 2 || !mode.Partial() || mode.GetMissingValuesForDisplay() != "Slow" {
+		t.Errorf("Expected spec.mode partially covered, missing Slow, found: %+v", mode)
+	}
+	if values := spec.Fields["paused"].Values; values.Len() != 1 || !values.Has("false") {
+		t.Errorf("Expected values [false] for spec.paused, found: %v", values.List())
+	}
+
+	containers := getTypeCoverage(typeCoverage, schemaTypeName+".spec.containers[]")
+	if containers == nil || !containers.Fields["name"].Coverage || containers.Fields["image"].Coverage {
+		t.Errorf("Expected only name covered in %s.spec.containers[], found: %+v", schemaTypeName, containers)
+	}
+}
+
+func TestSchemaResourceTreeMutation(t *testing.T) {
+	tree := getTestSchemaTree()
+	var old, updated interface{}
+	if err := json.Unmarshal([]byte(`{"spec": {"replicas": 2, "mode": "Fast", "containers": [{"name": "c"}], "labels": {"a": "b"}}}`), &old); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"spec": {"replicas": 3, "mode": "Fast", "containers": [{"name": "c", "image": "i"}], "labels": {"a": "b"}}}`), &updated); err != nil {
+		t.Fatal(err)
+	}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(updated), RequestInfo{OldObject: reflect.ValueOf(old)})
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Mutated: true})
+	spec := getTypeCoverage(typeCoverage, schemaTypeName+".spec")
+	if spec == nil {
+		t.Fatalf("Expected coverage of %s.spec", schemaTypeName)
+	}
+	for field, mutated := range map[string]bool{"replicas": true, "containers": true, "mode": false, "labels": false, "paused": false} {
+		if spec.Fields[field].Mutated != mutated {
+			t.Errorf("Expected spec.%s Mutated to be %t", field, mutated)
+		}
+	}
+	containers := getTypeCoverage(typeCoverage, schemaTypeName+".spec.containers[]")
+	if containers == nil || containers.Fields["name"].Mutated || !containers.Fields["image"].Mutated {
+		t.Errorf("Expected only image mutated in %s.spec.containers[], found: %+v", schemaTypeName, containers)
+	}
+}
+
+func TestSchemaResourceTreeIgnoresMismatchedValues(t *testing.T) {
+	tree := getTestSchemaTree()
+	var value interface{}
+	if err := json.Unmarshal([]byte(`{"spec": {"replicas": 0, "containers": "c", "labels": {}}}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	tree.UpdateCoverage(reflect.ValueOf(value))
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+	spec := getTypeCoverage(typeCoverage, schemaTypeName+".spec")
+	if spec == nil {
+		t.Fatalf("Expected coverage of %s.spec", schemaTypeName)
+	}
+	for field, coverage := range spec.Fields {
+		if coverage.Coverage {
+			t.Errorf("Expected spec.%s not covered by a zero, empty or mismatched value", field)
+		}
+	}
+}
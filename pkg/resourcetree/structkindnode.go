@@ -74,30 +74,68 @@ func isTimeNode(t reflect.Type) bool {
 	}
 }
 
-func (s *StructKindNode) updateCoverage(v reflect.Value) {
+func (s *StructKindNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	if v.IsValid() {
-		s.Covered = true
+		s.markCovered(updateHelper)
 		if !s.LeafNode {
 			for i := 0; i < v.NumField(); i++ {
-				s.Children[v.Type().Field(i).Name].updateCoverage(v.Field(i))
+				s.Children[v.Type().Field(i).Name].updateCoverage(v.Field(i), updateHelper)
+			}
+		}
+	}
+}
+
+func (s *StructKindNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	mutated := false
+	if s.LeafNode {
+		mutated = !valuesEqual(v, old)
+	} else {
+		for i := 0; i < v.NumField(); i++ {
+			if s.Children[v.Type().Field(i).Name].updateMutation(v.Field(i), old.Field(i)) {
+				mutated = true
+			}
+		}
+	}
+	if mutated {
+		s.markMutated()
+	}
+	return mutated
+}
+
+func (s *StructKindNode) updatePresence(raw reflect.Value) {
+	s.markPresent()
+	// eg: resource.Quantity, whose JSON is a string
+	if s.LeafNode || raw.Kind() != reflect.Map {
+		return
+	}
+	for i := 0; i < s.FieldType.NumField(); i++ {
+		field := s.FieldType.Field(i)
+		name, inline := jsonFieldName(field)
+		if inline {
+			s.Children[field.Name].updatePresence(raw)
+		} else if jsonEncoded(field) {
+			if value := rawElem(raw.MapIndex(reflect.ValueOf(name))); value.IsValid() {
+				s.Children[field.Name].updatePresence(value)
 			}
 		}
 	}
 }
 
 func (s *StructKindNode) buildCoverageData(coverageHelper coverageDataHelper) {
-	if len(s.Children) == 0 {
+	// Types reached through pointers, arrays and maps, or references, may have been covered already
+	if len(s.Children) == 0 || coverageHelper.coveredTypes.Has(s.FieldType.PkgPath()+"."+s.FieldType.Name()) {
 		return
 	}
 
-	coverage := s.Tree.Forest.getConnectedNodeCoverage(s.FieldType, coverageHelper.fieldRules, coverageHelper.ignoredFields)
+	coverage := s.Tree.Forest.getConnectedNodeCoverage(s.FieldType, coverageHelper)
 	*coverageHelper.typeCoverage = append(*coverageHelper.typeCoverage, coverage)
 	// Adding the type to covered fields so as to avoid revisiting the same node in other parts of the resource tree.
 	coverageHelper.coveredTypes.Insert(s.FieldType.PkgPath() + "." + s.FieldType.Name())
 
+	fields := jsonFields(s)
 	for field := range coverage.Fields {
-		node := s.Children[field]
-		if !coverage.Fields[field].Ignored && node.GetData().Covered && coverageHelper.nodeRules.Apply(node) {
+		node := fields[field]
+		if !coverage.Fields[field].Ignored && coverageHelper.outlined(node.GetData()) && coverageHelper.nodeRules.Apply(node) {
 			// Check to see if the type has already been covered.
 			if !coverageHelper.coveredTypes.Has(node.GetData().FieldType.PkgPath() + "." + node.GetData().FieldType.Name()) {
 				node.buildCoverageData(coverageHelper)
@@ -216,18 +216,27 @@ func verifyOtherTypeNode(data NodeData) error {
 
 	child := data.Children["structMap"]
 	d := child.GetData()
-	if d.FieldType.Kind() != reflect.Map || !d.LeafNode || len(d.Children) != 0 {
-		return fmt.Errorf("Unexpected field:structMap - Expected Kind: %s, LeafNode: true, Children:0 Found Kind: %s, LeafNode: %t, Children: %d",
+	if d.FieldType.Kind() != reflect.Map || d.LeafNode || len(d.Children) != 1 {
+		return fmt.Errorf("Unexpected field:structMap - Expected Kind: %s, LeafNode: false, Children:1 Found Kind: %s, LeafNode: %t, Children: %d",
 			reflect.Map, d.FieldType.Kind(), d.LeafNode, len(d.Children))
 	}
+	if err := verifyBaseTypeNode("structMap-map", d.Children["structMap-map"].GetData()); err != nil {
+		return err
+	}
 
 	child = data.Children["baseMap"]
 	d = child.GetData()
-	if d.FieldType.Kind() != reflect.Map || !d.LeafNode || len(d.Children) != 0 {
-		return fmt.Errorf("Unexpected field:structMap - Expected Kind: %s, LeafNode: true, Children: 0 Found kind: %s, LeafNode: %t, Children: %d",
+	if d.FieldType.Kind() != reflect.Map || d.LeafNode || len(d.Children) != 1 {
+		return fmt.Errorf("Unexpected field:baseMap - Expected Kind: %s, LeafNode: false, Children: 1 Found kind: %s, LeafNode: %t, Children: %d",
 			reflect.Map, d.FieldType.Kind(), d.LeafNode, len(d.Children))
 	}
 
+	d = d.Children["baseMap-map"].GetData()
+	if d.FieldType.Kind() != reflect.String || !d.LeafNode || len(d.Children) != 0 {
+		return fmt.Errorf("Unexpected field:baseMap-map Expected kind: %s, LeafNode: true, Children:0 Found: kind: %s, LeafNode: %t, Children:%d",
+			reflect.String, d.FieldType.Kind(), d.LeafNode, len(d.Children))
+	}
+
 	return nil
 }
 
@@ -391,6 +400,14 @@ func verifyOtherTypeValue(node NodeInterface) error {
 		return errors.New("field:structMap marked as not-Covered. Expected to be Covered")
 	}
 
+	if keys := node.GetData().Children["structMap"].getValues(); keys.Len() != 1 || !keys.Has("test") {
+		return fmt.Errorf("field:structMap Expected keys [test] Found: %v", keys.List())
+	}
+
+	if err := verifyBaseTypeValue("structMap-map", node.GetData().Children["structMap"].GetData().Children["structMap-map"]); err != nil {
+		return err
+	}
+
 	if node.GetData().Children["baseMap"].GetData().Covered {
 		return errors.New("field:baseMap marked as Covered. Expected to be not-Covered")
 	}
@@ -47,14 +47,26 @@ func (ti *TimeTypeNode) initialize(field string, parent NodeInterface, t reflect
 
 func (ti *TimeTypeNode) buildChildNodes(t reflect.Type) {}
 
-func (ti *TimeTypeNode) updateCoverage(v reflect.Value) {
+func (ti *TimeTypeNode) updateCoverage(v reflect.Value, updateHelper updateCoverageHelper) {
 	if v.Type().Kind() == reflect.Struct && v.IsValid() {
-		ti.Covered = true
+		ti.markCovered(updateHelper)
 	} else if v.Type().Kind() == reflect.Ptr && !v.IsNil() {
-		ti.Covered = true
+		ti.markCovered(updateHelper)
 	}
 }
 
+func (ti *TimeTypeNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	if valuesEqual(v, old) {
+		return false
+	}
+	ti.markMutated()
+	return true
+}
+
+func (ti *TimeTypeNode) updatePresence(raw reflect.Value) {
+	ti.markPresent()
+}
+
 // no-op as the coverage is calculated as field coverage in parent node.
 func (ti *TimeTypeNode) buildCoverageData(coverageHelper coverageDataHelper) {}
 
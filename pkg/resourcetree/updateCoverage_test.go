@@ -17,8 +17,18 @@ limitations under the License.
 package resourcetree
 
 import (
+	"container/list"
+	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 )
 
 func TestSimpleStructValue(t *testing.T) {
@@ -59,3 +69,253 @@ func TestUpdateCoverage(t *testing.T) {
 
 	}
 }
+
+func TestUpdateCoverageFromRequest(t *testing.T) {
+	tree := getTestTree(basicTypeName, reflect.TypeOf(baseType{}))
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(getBaseTypeValue()), RequestInfo{Test: "test-a"})
+	if err := verifyBaseTypeValue("", tree.Root); err != nil {
+		t.Fatal(err)
+	}
+	if tests := tree.Root.GetData().Children["field1"].GetData().Tests; !tests.Has("test-a") {
+		t.Fatalf("field1 expected to be covered by test-a, found tests: %v", tests.List())
+	}
+
+	for _, test := range []string{"", "test-a"} {
+		typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Test: test})
+		if len(typeCoverage) != 1 {
+			t.Fatalf("Test %q: expected coverage for 1 type, found: %d", test, len(typeCoverage))
+		}
+		if !typeCoverage[0].Fields["field1"].Coverage {
+			t.Errorf("Test %q: field1 marked as not-Covered. Expected to be Covered", test)
+		}
+	}
+
+	// test-b never sent this resource, so it covers none of its types
+	if typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Test: "test-b"}); len(typeCoverage) != 0 {
+		t.Fatalf("Test \"test-b\": expected coverage for 0 types, found: %d", len(typeCoverage))
+	}
+}
+
+func TestUpdateCoverageFromRequestMutation(t *testing.T) {
+	tree := getTestTree(ptrTypeName, reflect.TypeOf(ptrType{}))
+	old := ptrType{structPtr: &baseType{field1: "test"}}
+	updated := ptrType{structPtr: &baseType{field1: "test", field2: 5}}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(updated), RequestInfo{Operation: "UPDATE", OldObject: reflect.ValueOf(old)})
+
+	structPtr := tree.Root.GetData().Children["structPtr"]
+	base := structPtr.GetData().Children["structPtr-ptr"]
+	if !tree.Root.GetData().Mutated || !structPtr.GetData().Mutated || !base.GetData().Mutated {
+		t.Errorf("Expected the path to the changed field2 marked as Mutated")
+	}
+	if base.GetData().Children["field1"].GetData().Mutated {
+		t.Errorf("field1 marked as Mutated. Expected to be not-Mutated as it was sent back unchanged")
+	}
+	if !base.GetData().Children["field2"].GetData().Mutated {
+		t.Errorf("field2 marked as not-Mutated. Expected to be Mutated")
+	}
+	if tree.Root.GetData().Children["basePtr"].GetData().Mutated {
+		t.Errorf("basePtr marked as Mutated. Expected to be not-Mutated as it is nil in both")
+	}
+
+	typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Mutated: true})
+	coverageValues := coveragecalculator.CalculateTypeCoverage(typeCoverage)
+	// structPtr, and field2 of baseType
+	if coverageValues.CoveredFields != 2 || coverageValues.MutatedFields != 2 {
+		t.Errorf("Expected 2 fields covered and mutated, found: %+v", coverageValues)
+	}
+}
+
+type presenceMeta struct {
+	Kind string `json:"kind"`
+}
+
+type presenceItem struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+type presenceType struct {
+	presenceMeta `json:",inline"`
+	Name         string         `json:"name"`
+	Enabled      bool           `json:"enabled"`
+	Count        *int32         `json:"count,omitempty"`
+	Items        []presenceItem `json:"items"`
+	Skipped      string         `json:"-"`
+}
+
+func TestUpdateCoverageFromRequestPresence(t *testing.T) {
+	tree := getTestTree("presenceType", reflect.TypeOf(presenceType{}))
+	raw := []byte(`{"kind": "K", "name": "", "enabled": false, "count": null, "items": [{"name": "item"}], "Skipped": "s"}`)
+	value := presenceType{}
+	var rawValue interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(raw, &rawValue); err != nil {
+		t.Fatal(err)
+	}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(value), RequestInfo{RawObject: reflect.ValueOf(rawValue)})
+
+	root := tree.Root.GetData()
+	for field, present := range map[string]bool{"presenceMeta": true, "Name": true, "Enabled": true, "Count": false, "Items": true, "Skipped": false} {
+		if root.Children[field].GetData().Present != present {
+			t.Errorf("Expected %s Present to be %t", field, present)
+		}
+	}
+	if !root.Children["presenceMeta"].GetData().Children["Kind"].GetData().Present {
+		t.Errorf("Expected inlined Kind Present")
+	}
+	if root.Children["Name"].GetData().Covered {
+		t.Errorf("Expected Name set to \"\" to be Present, but not Covered")
+	}
+	item := root.Children["Items"].GetData().Children["Items-arr"].GetData()
+	if !item.Present || !item.Children["Name"].GetData().Present || item.Children["Enabled"].GetData().Present {
+		t.Errorf("Expected only Name Present in Items")
+	}
+}
+
+func TestUpdateCoverageFromRequestOperation(t *testing.T) {
+	tree := getTestTree(arrayTypeName, reflect.TypeOf(arrayType{}))
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(getArrValueSomeCovered()), RequestInfo{Operation: "CREATE"})
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(getArrValueAllCovered()), RequestInfo{Operation: "UPDATE"})
+
+	created := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Operation: "CREATE"})
+	updated := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Operation: "UPDATE"})
+	if createdValues, updatedValues := coveragecalculator.CalculateTypeCoverage(created), coveragecalculator.CalculateTypeCoverage(updated); createdValues.CoveredFields >= updatedValues.CoveredFields {
+		t.Errorf("Expected fewer fields covered on CREATE than on UPDATE, found: %d and %d", createdValues.CoveredFields, updatedValues.CoveredFields)
+	}
+	for _, coverage := range updated {
+		for field, fieldCoverage := range coverage.Fields {
+			if fieldCoverage.Coverage && !fieldCoverage.Operations.Has("UPDATE") {
+				t.Errorf("Expected %s.%s covered on UPDATE, found operations: %v", coverage.Type, field, fieldCoverage.Operations.List())
+			}
+		}
+	}
+
+	// Nothing was deleted, so nothing is covered on DELETE
+	if typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{Operation: "DELETE"}); len(typeCoverage) != 0 {
+		t.Fatalf("Operation DELETE: expected coverage for 0 types, found: %d", len(typeCoverage))
+	}
+}
+
+func TestUpdateCoverageFromRequestHits(t *testing.T) {
+	first := time.Date(2019, time.October, 1, 0, 0, 0, 0, time.UTC)
+	tree := getTestTree("enumType", reflect.TypeOf(enumType{}))
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(enumType{DNSPolicy: corev1.DNSClusterFirst}), RequestInfo{Time: first.Add(time.Hour)})
+	// Recorded out of order
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(enumType{DNSPolicy: corev1.DNSClusterFirst}), RequestInfo{Time: first})
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(enumType{DNSPolicy: corev1.DNSDefault}), RequestInfo{Time: first.Add(2 * time.Hour)})
+
+	verify := func(tree *ResourceTree) {
+		typeCoverage := tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{})
+		root := getTypeCoverage(typeCoverage, "enumType")
+		if root == nil {
+			t.Fatalf("Expected coverage of enumType")
+		}
+		dnsPolicy := root.Fields["dnsPolicy"]
+		if dnsPolicy.Hits != 3 || !dnsPolicy.FirstSeen.Equal(first) || !dnsPolicy.LastSeen.Equal(first.Add(2*time.Hour)) {
+			t.Errorf("Expected dnsPolicy hit 3 times between %v and %v, found: %+v", first, first.Add(2*time.Hour), dnsPolicy)
+		}
+		if expected := map[string]int{"ClusterFirst": 2, "Default": 1}; !reflect.DeepEqual(dnsPolicy.ValueHits, expected) {
+			t.Errorf("Expected dnsPolicy value hits %v, found: %v", expected, dnsPolicy.ValueHits)
+		}
+		if name := root.Fields["name"]; name.Hits != 0 || !name.FirstSeen.IsZero() {
+			t.Errorf("Expected name never hit, found: %+v", name)
+		}
+	}
+	verify(tree)
+
+	data, err := json.Marshal(tree.Forest.GetCoverageState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := CoverageState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	restored := getTestTree("enumType", reflect.TypeOf(enumType{}))
+	restored.Forest.RestoreCoverageState(state)
+	verify(restored)
+}
+
+func TestUpdateCoverageFromRequestExemplars(t *testing.T) {
+	tree := getTestTree("enumType", reflect.TypeOf(enumType{}))
+	for _, name := range []string{"a", "a", "b", "c", "d"} {
+		tree.UpdateCoverageFromRequest(reflect.ValueOf(enumType{Name: "x"}), RequestInfo{Namespace: "default", Name: name, Operation: "CREATE"})
+	}
+	// Requests for objects that aren't known, eg: in tests, have no exemplars
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(enumType{DNSPolicy: corev1.DNSDefault}), RequestInfo{})
+
+	data, err := json.Marshal(tree.Forest.GetCoverageState())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := CoverageState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	restored := getTestTree("enumType", reflect.TypeOf(enumType{}))
+	restored.Forest.RestoreCoverageState(state)
+
+	for _, tree := range []*ResourceTree{tree, restored} {
+		root := getTypeCoverage(tree.BuildCoverageData(NodeRules{}, FieldRules{}, coveragecalculator.IgnoredFields{}, CoverageOptions{}), "enumType")
+		if root == nil {
+			t.Fatalf("Expected coverage of enumType")
+		}
+		names := []string{}
+		for _, exemplar := range root.Fields["name"].Exemplars {
+			if exemplar.Namespace != "default" || exemplar.Operation != "CREATE" {
+				t.Errorf("Expected exemplars created in default, found: %+v", exemplar)
+			}
+			names = append(names, exemplar.Name)
+		}
+		if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(names, expected) {
+			t.Errorf("Expected the first %d objects as exemplars of name %v, found: %v", coveragecalculator.MaxExemplars, expected, names)
+		}
+		if exemplars := root.Fields["dnsPolicy"].Exemplars; len(exemplars) != 0 {
+			t.Errorf("Expected no exemplars of dnsPolicy, found: %+v", exemplars)
+		}
+	}
+}
+
+type specialType struct {
+	Quantity  resource.Quantity    `json:"quantity"`
+	Limit     *resource.Quantity   `json:"limit"`
+	Port      intstr.IntOrString   `json:"port"`
+	Extension runtime.RawExtension `json:"extension"`
+}
+
+func TestUpdateCoverageSpecialTypes(t *testing.T) {
+	forest := ResourceForest{
+		ConnectedNodes:  make(map[string]*list.List),
+		TopLevelTrees:   make(map[string]ResourceTree),
+		RawExtensionKey: func(gvk schema.GroupVersionKind) string { return gvk.Kind },
+	}
+	forest.AddResourceTree("specialType", "specialType", reflect.TypeOf(specialType{}))
+	forest.AddResourceTree("presenceType", "presenceType", reflect.TypeOf(presenceType{}))
+	tree := forest.TopLevelTrees["specialType"]
+	value := specialType{}
+	if err := json.Unmarshal([]byte(`{"quantity": "1Gi", "limit": "100m", "port": "http", "extension": {"apiVersion": "v1", "kind": "presenceType", "name": "n"}}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	tree.UpdateCoverageFromRequest(reflect.ValueOf(value), RequestInfo{Test: "test-a"})
+
+	root := tree.Root.GetData()
+	limit := root.Children["Limit"].GetData().Children["Limit-ptr"]
+	for field, node := range map[string]NodeInterface{"Quantity": root.Children["Quantity"], "Limit-ptr": limit, "Port": root.Children["Port"], "Extension": root.Children["Extension"]} {
+		if data := node.GetData(); !data.Covered || !data.LeafNode || len(data.Children) != 0 {
+			t.Errorf("Expected %s a covered leaf node, found: Covered: %t, LeafNode: %t, Children: %d", field, data.Covered, data.LeafNode, len(data.Children))
+		}
+	}
+	for field, expected := range map[string]NodeInterface{"BinarySI": root.Children["Quantity"], "DecimalSI": limit, "String": root.Children["Port"], "v1/presenceType": root.Children["Extension"]} {
+		if values := expected.getValues(); values.Len() != 1 || !values.Has(field) {
+			t.Errorf("Expected values [%s], found: %v", field, values.List())
+		}
+	}
+
+	// The embedded object is recorded in its own tree
+	embedded := forest.TopLevelTrees["presenceType"].Root.GetData()
+	if name := embedded.Children["Name"].GetData(); !name.Covered || !name.Tests.Has("test-a") || !name.Present {
+		t.Errorf("Expected Name of the embedded presenceType covered by test-a, found: %+v", name)
+	}
+}
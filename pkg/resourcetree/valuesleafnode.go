@@ -0,0 +1,77 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcetree
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// valuesLeafNode is embedded by leaf nodes of structs that aren't encoded in
+// JSON as objects, e.g. QuantityTypeNode, and that record a description of
+// each value seen, e.g. the format of a quantity, as their values. Embedding
+// nodes only implement updateCoverage.
+type valuesLeafNode struct {
+	NodeData
+	values sets.String
+}
+
+// GetData returns node data
+func (l *valuesLeafNode) GetData() NodeData {
+	return l.NodeData
+}
+
+func (l *valuesLeafNode) initialize(field string, parent NodeInterface, t reflect.Type, rt *ResourceTree) {
+	l.NodeData.initialize(field, parent, t, rt)
+	l.values = sets.String{}
+	l.LeafNode = true
+}
+
+func (l *valuesLeafNode) buildChildNodes(t reflect.Type) {}
+
+func (l *valuesLeafNode) updateMutation(v reflect.Value, old reflect.Value) bool {
+	if valuesEqual(v, old) {
+		return false
+	}
+	l.markMutated()
+	return true
+}
+
+func (l *valuesLeafNode) updatePresence(raw reflect.Value) {
+	l.markPresent()
+}
+
+// no-op as the coverage is calculated as field coverage in parent node.
+func (l *valuesLeafNode) buildCoverageData(coverageHelper coverageDataHelper) {}
+
+func (l *valuesLeafNode) getValues() sets.String {
+	return l.values
+}
+
+func (l *valuesLeafNode) coverageState() NodeCoverageState {
+	state := l.NodeData.coverageState()
+	if l.values.Len() != 0 {
+		state.Values = l.values.List()
+	}
+	return state
+}
+
+func (l *valuesLeafNode) restoreCoverageState(state NodeCoverageState) {
+	l.NodeData.restoreCoverageState(state)
+	l.values.Insert(state.Values...)
+}
@@ -19,6 +19,7 @@ package rules
 import (
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/k8s-api-coverage/pkg/resourcetree"
 )
 
@@ -60,3 +61,18 @@ var FieldRules = resourcetree.FieldRules{
 		IgnoreDeprecatedFields,
 	},
 }
+
+// ExpectedMapKeys are the well-known keys of maps, see resourcetree.ResourceForest.ExpectedMapKeys.
+var ExpectedMapKeys = map[string][]string{
+	"k8s.io/api/core/v1.ResourceList": {
+		string(corev1.ResourceCPU),
+		string(corev1.ResourceMemory),
+		string(corev1.ResourceEphemeralStorage),
+		corev1.ResourceHugePagesPrefix + "2Mi",
+		corev1.ResourceHugePagesPrefix + "1Gi",
+	},
+	"k8s.io/api/core/v1.PodSpec.nodeSelector": {
+		corev1.LabelOSStable,
+		corev1.LabelArchStable,
+	},
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"os/user"
 	"path"
@@ -50,6 +51,9 @@ const (
 	// WebhookResourceCoverageEndPoint constant for resource coverage API endpoint.
 	WebhookResourceCoverageEndPoint = "%s" + webhook.ResourceCoverageEndPoint + "?resource=%s"
 
+	// WebhookResourcePathCoverageEndPoint constant for resource path coverage API endpoint.
+	WebhookResourcePathCoverageEndPoint = "%s" + webhook.ResourcePathCoverageEndPoint + "?resource=%s"
+
 	// WebhookTotalCoverageEndPoint constant for total coverage API endpoint.
 	WebhookTotalCoverageEndPoint = "%s" + webhook.TotalCoverageEndPoint
 
@@ -128,13 +132,7 @@ func httpGet(requestURI string) ([]byte, error) {
 
 // GetResourceCoverage is a helper method to get Coverage data for a resource from the service webhook.
 func GetResourceCoverage(webhookURI string, gvk schema.GroupVersionKind) (string, error) {
-	// TODO(spiffxp): this needs to handle same Kind, different GroupVersion (eg: CreationOptions, ListOptions, knative Service vs. k8s Service)
-	requestURI := fmt.Sprintf(WebhookResourceCoverageEndPoint, webhookURI, gvk.Kind)
-	body, err := httpGet(requestURI)
-	if err != nil {
-		return "", err
-	}
-	return string(body), nil
+	return getCoverage(WebhookResourceCoverageEndPoint, webhookURI, webhook.ResourceKey(gvk))
 }
 
 // GetAndWriteResourceCoverage is a helper method that uses GetResourceCoverage to get coverage and write it to a file.
@@ -146,6 +144,55 @@ func GetAndWriteResourceCoverage(webhookURI string, gvk schema.GroupVersionKind,
 	return ioutil.WriteFile(outputFile, []byte(resourceCoverage), 0400)
 }
 
+// GetResourcePathCoverage is a helper method to get Coverage data for a resource, outlined per path, from the service webhook.
+func GetResourcePathCoverage(webhookURI string, gvk schema.GroupVersionKind) (string, error) {
+	return getCoverage(WebhookResourcePathCoverageEndPoint, webhookURI, webhook.ResourceKey(gvk))
+}
+
+// GetAndWriteResourcePathCoverage is a helper method that uses GetResourcePathCoverage to get coverage and write it to a file.
+func GetAndWriteResourcePathCoverage(webhookURI string, gvk schema.GroupVersionKind, outputFile string) error {
+	resourceCoverage, err := GetResourcePathCoverage(webhookURI, gvk)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(outputFile, []byte(resourceCoverage), 0400)
+}
+
+// GetSubresourceCoverage is a helper method to get Coverage data for a subresource from the service webhook.
+func GetSubresourceCoverage(webhookURI string, subresource webhook.Subresource) (string, error) {
+	return getCoverage(WebhookResourceCoverageEndPoint, webhookURI, webhook.SubresourceKey(subresource))
+}
+
+// GetAndWriteSubresourceCoverage is a helper method that uses GetSubresourceCoverage to get coverage and write it to a file.
+func GetAndWriteSubresourceCoverage(webhookURI string, subresource webhook.Subresource, outputFile string) error {
+	subresourceCoverage, err := GetSubresourceCoverage(webhookURI, subresource)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(outputFile, []byte(subresourceCoverage), 0400)
+}
+
+func getCoverage(endPoint string, webhookURI string, key string) (string, error) {
+	requestURI := fmt.Sprintf(endPoint, webhookURI, url.QueryEscape(key))
+	body, err := httpGet(requestURI)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// WriteResourceCoverage writes resource coverage data computed locally, e.g.
+// from a replayed audit log, to a HTML output file.
+func WriteResourceCoverage(outputFile string, typeCoverage []coveragecalculator.TypeCoverage,
+	coverageValues coveragecalculator.CoverageValues) error {
+	htmlData, err := view.GetHTMLDisplay(typeCoverage, coverageValues)
+	if err != nil {
+		return errors.Wrap(err, "Failed building html file from resource coverage. error")
+	}
+
+	return ioutil.WriteFile(outputFile, []byte(htmlData), 0400)
+}
+
 // GetTotalCoverage calls the total coverage API to retrieve total coverage values.
 func GetTotalCoverage(webhookURI string) (coveragecalculator.CoverageValues, error) {
 	coverage := coveragecalculator.CoverageValues{}
@@ -168,7 +215,11 @@ func GetAndWriteTotalCoverage(webhookURI string, outputFile string) error {
 	if err != nil {
 		return err
 	}
+	return WriteTotalCoverage(outputFile, totalCoverage)
+}
 
+// WriteTotalCoverage writes total coverage values to a HTML output file.
+func WriteTotalCoverage(outputFile string, totalCoverage coveragecalculator.CoverageValues) error {
 	htmlData, err := view.GetHTMLCoverageValuesDisplay(totalCoverage)
 	if err != nil {
 		return errors.Wrap(err, "Failed building html file from total coverage. error")
@@ -199,7 +250,7 @@ func WriteResourcePercentages(outputFile string,
 	coveragePercentages coveragecalculator.CoveragePercentages) error {
 	htmlData, err := view.GetCoveragePercentageXMLDisplay(coveragePercentages)
 	if err != nil {
-		errors.Wrap(err, "Failed building coverage percentage xml file")
+		return errors.Wrap(err, "Failed building coverage percentage xml file")
 	}
 
 	return ioutil.WriteFile(outputFile, []byte(htmlData), 0400)
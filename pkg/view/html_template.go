@@ -37,6 +37,20 @@ var TypeCoverageTempl = fmt.Sprint(`<!DOCTYPE html>
 
   .values {color: yellow; size: A3}
 
+  .tests {color: lightblue; size: A3}
+
+  .operations {color: orange; size: A3}
+
+  .mutated {color: magenta; size: A3}
+
+  .present {color: cyan; size: A3}
+
+  .partial {color: gold; size: A3}
+
+  .hits {color: silver; size: A3}
+
+  .exemplars {color: lightgreen; size: A3}
+
   table, th, td { border: 1px solid white; text-align: center}
 
   .braces {color: white; size: A3}
@@ -60,9 +74,43 @@ var TypeCoverageTempl = fmt.Sprint(`<!DOCTYPE html>
           {{if gt $valueLen 0 }}
             &emsp; &emsp; <span class="values">Values: [{{$value.GetValuesForDisplay}}]</span>
           {{end}}
+          {{ $expectedLen := len $value.Expected }}
+          {{if gt $expectedLen 0 }}
+            &emsp; &emsp; <span class="partial">{{$value.GetExpectedSeen}} of {{$expectedLen}} values seen{{if $value.Partial }}, missing: [{{$value.GetMissingValuesForDisplay}}]{{end}}</span>
+          {{end}}
+          {{if $value.Mutated }}
+            &emsp; &emsp; <span class="mutated">Mutated</span>
+          {{end}}
+          {{if gt $value.Hits 0 }}
+            &emsp; &emsp; <span class="hits">Hits: {{$value.Hits}}, {{$value.GetSeenForDisplay}}</span>
+          {{end}}
+          {{ $operationsLen := len $value.Operations }}
+          {{if gt $operationsLen 0 }}
+            &emsp; &emsp; <span class="operations">Operations: [{{$value.GetOperationsForDisplay}}]</span>
+          {{end}}
+          {{ $testsLen := len $value.Tests }}
+          {{if gt $testsLen 0 }}
+            <details class="tests tab"><summary>Tests: {{ $testsLen }}</summary>
+              {{ range $test := $value.GetTestsForDisplay }}
+                <div class="tab">{{ $test }}</div>
+              {{end}}
+            </details>
+          {{end}}
+          {{ $exemplarsLen := len $value.Exemplars }}
+          {{if gt $exemplarsLen 0 }}
+            <details class="exemplars tab"><summary>Exemplars: {{ $exemplarsLen }}</summary>
+              {{ range $exemplar := $value.Exemplars }}
+                <div class="tab">{{ $exemplar }}</div>
+              {{end}}
+            </details>
+          {{end}}
         </div>
       {{else}}
-        <div class="notcovered tab">{{ $value.Field }}</div>
+        <div class="notcovered tab">{{ $value.Field }}
+          {{if $value.Present }}
+            &emsp; &emsp; <span class="present">Present, zero value</span>
+          {{end}}
+        </div>
       {{end}}
     {{end}}
     <div class="braces">}</div>
@@ -77,6 +125,9 @@ var TypeCoverageTempl = fmt.Sprint(`<!DOCTYPE html>
   <tr class="styleheader"><td>Total Fields</td><td>{{ .CoverageNumbers.TotalFields }}</td></tr>
   <tr class="styleheader"><td>Covered Fields</td><td>{{ .CoverageNumbers.CoveredFields }}</td></tr>
   <tr class="styleheader"><td>Ignored Fields</td><td>{{ .CoverageNumbers.IgnoredFields }}</td></tr>
+  <tr class="styleheader"><td>Mutated Fields</td><td>{{ .CoverageNumbers.MutatedFields }}</td></tr>
+  <tr class="styleheader"><td>Present Fields</td><td>{{ .CoverageNumbers.PresentFields }}</td></tr>
+  <tr class="styleheader"><td>Partially Covered Fields</td><td>{{ .CoverageNumbers.PartialFields }}</td></tr>
   <tr class="styleheader"><td>Coverage Percentage</td><td>{{ .CoverageNumbers.PercentCoverage }}</td></tr>
 </table>
 </body>
@@ -104,6 +155,9 @@ var AggregateCoverageTmpl = fmt.Sprint(`<!DOCTYPE html>
   <tr class="styleheader"><td>Total Fields</td><td>{{ .TotalFields }}</td></tr>
   <tr class="styleheader"><td>Covered Fields</td><td>{{ .CoveredFields }}</td></tr>
   <tr class="styleheader"><td>Ignored Fields</td><td>{{ .IgnoredFields }}</td></tr>
+  <tr class="styleheader"><td>Mutated Fields</td><td>{{ .MutatedFields }}</td></tr>
+  <tr class="styleheader"><td>Present Fields</td><td>{{ .PresentFields }}</td></tr>
+  <tr class="styleheader"><td>Partially Covered Fields</td><td>{{ .PartialFields }}</td></tr>
   <tr class="styleheader"><td>Coverage Percentage</td><td>{{ .PercentCoverage }}</td></tr>
 </table>
 </body>
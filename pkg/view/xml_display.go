@@ -32,7 +32,7 @@ func GetCoveragePercentageXMLDisplay(percentageCoverages coveragecalculator.Cove
 	}
 
 	var buffer strings.Builder
-	err = tmpl.Execute(&buffer, percentageCoverages)
+	err = tmpl.Execute(&buffer, &percentageCoverages)
 	if err != nil {
 		return "", err
 	}
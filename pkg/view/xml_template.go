@@ -38,4 +38,26 @@ var JunitResultTmpl = fmt.Sprint(`<testsuites>
       </testcase>
     {{end}}
   </testsuite>
+  {{ if .SubresourceCoverages }}
+  <testsuite name="subresources" time="0" failures="0" tests="0">
+    {{ range $key, $value := .SubresourceCoverages }}
+      <testcase name="{{ $key }}" time="0" classname="go_coverage">
+        <properties>
+          <property name="coverage" value="{{ $value }}"/>
+        </properties>
+      </testcase>
+    {{end}}
+  </testsuite>
+  {{end}}
+  {{ if .OptionsCoverages }}
+  <testsuite name="options" time="0" failures="0" tests="0">
+    {{ range $key, $value := .OptionsCoverages }}
+      <testcase name="{{ $key }}" time="0" classname="go_coverage">
+        <properties>
+          <property name="coverage" value="{{ $value }}"/>
+        </properties>
+      </testcase>
+    {{end}}
+  </testsuite>
+  {{end}}
 </testsuites>`)
@@ -23,13 +23,24 @@ import (
 	"net/http"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
-	"k8s.io/api/admission/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/k8s-api-coverage/pkg/checkpoint"
 	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
 	"sigs.k8s.io/k8s-api-coverage/pkg/resourcetree"
 	"sigs.k8s.io/k8s-api-coverage/pkg/view"
@@ -37,15 +48,37 @@ import (
 
 var (
 	decoder = serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
+
+	// admissionReviewGroupVersions are the AdmissionReview apiVersions
+	// RecordResourceCoverage understands.
+	admissionReviewGroupVersions = sets.NewString(admissionv1.SchemeGroupVersion.String(), admissionv1beta1.SchemeGroupVersion.String())
+
+	// operations are the admission operations coverage can be filtered by.
+	operations = sets.NewString(string(admissionv1.Create), string(admissionv1.Update), string(admissionv1.Delete), string(admissionv1.Connect))
+
+	// operationOptionsKinds are the kinds of the options sent along with
+	// requests of each admission operation.
+	operationOptionsKinds = map[admissionv1.Operation]string{
+		admissionv1.Create: "CreateOptions",
+		admissionv1.Update: "UpdateOptions",
+		admissionv1.Delete: "DeleteOptions",
+	}
 )
 
 const (
-	// ResourceQueryParam query param name to provide the resource.
+	// ResourceQueryParam query param name to provide the resource, either as
+	// its full ResourceKey (eg: apps/v1/Deployment) or SubresourceKey (eg:
+	// v1/pods/exec), or as any unambiguous suffix of it (eg: Deployment, or
+	// v1/Deployment)
 	ResourceQueryParam = "resource"
 
 	// ResourceCoverageEndPoint is the endpoint for Resource Coverage API
 	ResourceCoverageEndPoint = "/resourcecoverage"
 
+	// ResourcePathCoverageEndPoint is the endpoint for Resource Coverage API
+	// outlined per path from the resource instead of per type
+	ResourcePathCoverageEndPoint = "/resourcepathcoverage"
+
 	// TotalCoverageEndPoint is the endpoint for Total Coverage API
 	TotalCoverageEndPoint = "/totalcoverage"
 
@@ -53,13 +86,64 @@ const (
 	// coverages API
 	ResourcePercentageCoverageEndPoint = "/resourcepercentagecoverage"
 
+	// TestQueryParam query param name to provide the test.
+	TestQueryParam = "test"
+
+	// OperationQueryParam query param name to only consider coverage recorded
+	// from requests of an admission operation, eg: CREATE or UPDATE.
+	OperationQueryParam = "operation"
+
+	// MutatedQueryParam query param name to consider fields whose value was
+	// changed by an update, instead of fields that were covered.
+	MutatedQueryParam = "mutated"
+
+	// AllTypesQueryParam query param name to count the fields of every type
+	// reachable from resources, not only those of types that were covered.
+	AllTypesQueryParam = "alltypes"
+
+	// FormatQueryParam query param name to select the format resource
+	// coverage is served in, html (the default) or json.
+	FormatQueryParam = "format"
+
+	// formatJSON is the FormatQueryParam value to serve coverage as JSON.
+	formatJSON = "json"
+
+	// TestCoverageEndPoint is the endpoint for Test Coverage API
+	TestCoverageEndPoint = "/testcoverage"
+
+	// TestNameAnnotation is the annotation a test can set on the objects it
+	// sends, to have their coverage attributed to it.
+	TestNameAnnotation = "k8s-api-coverage.sigs.k8s.io/test"
+
+	// userAgentTestSeparator separates the client's user agent from the name
+	// of the test, in user agents set by the kubernetes e2e framework.
+	userAgentTestSeparator = " -- "
+
 	// resourceChannelQueueSize size of the queue maintained for resource channel.
 	resourceChannelQueueSize = 10
 )
 
 type resourceChannelMsg struct {
-	resourceGVK      schema.GroupVersionKind
+	resourceGVK schema.GroupVersionKind
+	// subresource the resource was sent to, if it is setup in SubresourceMap
+	subresource      *Subresource
 	rawResourceValue []byte
+	// userAgent of the client that sent the resource, only known when the
+	// resource came from an audit event
+	userAgent string
+	// username of the user that sent the resource
+	username string
+	// operation the resource was sent with, eg: CREATE
+	operation string
+	// rawOldResourceValue is the resource an update replaced, if known
+	rawOldResourceValue []byte
+	// timestamp the resource was sent at, or when the webhook received it
+	timestamp time.Time
+	// namespace, name and uid of the object the resource was sent to, if
+	// known, eg: for options, which have no metadata of their own
+	namespace string
+	name      string
+	uid       string
 }
 
 // APICoverageRecorder type contains resource tree to record API coverage for resources.
@@ -67,58 +151,260 @@ type APICoverageRecorder struct {
 	Logger         *zap.SugaredLogger
 	ResourceForest resourcetree.ResourceForest
 	ResourceMap    map[schema.GroupVersionKind]reflect.Type
-	NodeRules      resourcetree.NodeRules
-	FieldRules     resourcetree.FieldRules
-	DisplayRules   view.DisplayRules
+	// SchemaMap identifies resources without Go types, eg: custom resources,
+	// whose APICoverage is calculated from their OpenAPI v3 schema instead.
+	SchemaMap map[schema.GroupVersionKind]*apiextensionsv1.JSONSchemaProps
+	// SubresourceMap identifies subresources whose request bodies are not the
+	// resource itself, eg: the PodExecOptions sent to pods/exec, and the
+	// reflect.Type of those bodies. Their APICoverage is calculated separately
+	// from that of resources.
+	SubresourceMap map[Subresource]reflect.Type
+	// OptionsMap identifies the options sent along with requests, eg:
+	// DeleteOptions, and their reflect.Type. Their APICoverage is calculated
+	// separately from that of resources.
+	OptionsMap   map[schema.GroupVersionKind]reflect.Type
+	NodeRules    resourcetree.NodeRules
+	FieldRules   resourcetree.FieldRules
+	DisplayRules view.DisplayRules
+	// IgnoredFieldsFile is the path of the .yaml file listing fields to be
+	// ignored, defaults to ignoredfields.yaml under $KO_DATA_PATH
+	IgnoredFieldsFile string
+	// CheckpointStore, if set, is where coverage state is restored from by
+	// Init, and checkpointed to by RunCheckpoints and Checkpoint
+	CheckpointStore checkpoint.Store
+	// CheckpointInterval is how often RunCheckpoints checkpoints coverage state
+	CheckpointInterval time.Duration
+	// AllTypes, if set, counts the fields of every type reachable from
+	// resources in all coverage built, not only those of types that were
+	// covered, so that coverage percentages have a stable denominator
+	AllTypes bool
 
 	resourceChannel chan resourceChannelMsg
-	ignoredFields   coveragecalculator.IgnoredFields
+	// stopUpdates is closed by Stop, and updatesDone once the resources
+	// queued in resourceChannel have been recorded
+	stopUpdates   chan struct{}
+	updatesDone   chan struct{}
+	ignoredFields coveragecalculator.IgnoredFields
 }
 
 // Init initializes the resources trees for set resources.
 func (a *APICoverageRecorder) Init() {
 	a.Logger.Info("APICoverageRecorder.Init")
 
+	// Objects embedded in other resources, eg: by a ControllerRevision, are
+	// recorded in the trees of their own kinds
+	a.ResourceForest.RawExtensionKey = ResourceKey
 	for resourceKind, resourceType := range a.ResourceMap {
-		a.ResourceForest.AddResourceTree(resourceKind.Kind, resourceType)
+		a.ResourceForest.AddResourceTree(ResourceKey(resourceKind), resourceKind.Kind, resourceType)
+	}
+	for resourceKind, resourceSchema := range a.SchemaMap {
+		if _, ok := a.ResourceMap[resourceKind]; ok {
+			continue
+		}
+		a.ResourceForest.AddSchemaResourceTree(ResourceKey(resourceKind), resourceKind.Kind, resourceKind.GroupVersion().String(), resourceSchema)
+	}
+	for subresource, bodyType := range a.SubresourceMap {
+		a.ResourceForest.AddResourceTree(SubresourceKey(subresource), bodyType.Name(), bodyType)
+	}
+	for optionsKind, optionsType := range a.OptionsMap {
+		a.ResourceForest.AddResourceTree(ResourceKey(optionsKind), optionsKind.Kind, optionsType)
 	}
 
-	ignoredFieldsFilePath := os.Getenv("KO_DATA_PATH") + "/ignoredfields.yaml"
+	ignoredFieldsFilePath := a.IgnoredFieldsFile
+	if ignoredFieldsFilePath == "" {
+		ignoredFieldsFilePath = os.Getenv("KO_DATA_PATH") + "/ignoredfields.yaml"
+	}
 	err := a.ignoredFields.ReadFromFile(ignoredFieldsFilePath)
 	if err != nil {
 		a.Logger.Errorf("Error reading file %s: %v", ignoredFieldsFilePath, err)
 	}
 
+	if a.CheckpointStore != nil {
+		if err := a.restoreCheckpoint(); err != nil {
+			a.Logger.Errorf("Error restoring coverage checkpoint: %v", err)
+		}
+	}
+
 	a.resourceChannel = make(chan resourceChannelMsg, resourceChannelQueueSize)
+	a.stopUpdates = make(chan struct{})
+	a.updatesDone = make(chan struct{})
 
 	go a.updateResourceCoverageTree()
 }
 
-// updateResourceCoverageTree updates the resource coverage tree.
+// restoreCheckpoint restores coverage state from the CheckpointStore.
+func (a *APICoverageRecorder) restoreCheckpoint() error {
+	data, err := a.CheckpointStore.Load()
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		a.Logger.Info("No coverage checkpoint to restore")
+		return nil
+	}
+	state := resourcetree.CoverageState{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("error unmarshalling coverage checkpoint: %v", err)
+	}
+	a.ResourceForest.RestoreCoverageState(state)
+	a.Logger.Infof("Restored coverage checkpoint for %d resources", len(state))
+	return nil
+}
+
+// Checkpoint saves coverage state to the CheckpointStore.
+func (a *APICoverageRecorder) Checkpoint() error {
+	data, err := json.Marshal(a.ResourceForest.GetCoverageState())
+	if err != nil {
+		return fmt.Errorf("error marshalling coverage checkpoint: %v", err)
+	}
+	return a.CheckpointStore.Save(data)
+}
+
+// RunCheckpoints checkpoints coverage state every CheckpointInterval, until
+// stop is closed. Callers should Stop and Checkpoint once more after stop is closed.
+func (a *APICoverageRecorder) RunCheckpoints(stop <-chan struct{}) {
+	a.Logger.Info("APICoverageRecorder.RunCheckpoints")
+	ticker := time.NewTicker(a.CheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Checkpoint(); err != nil {
+				a.Logger.Errorf("Error saving coverage checkpoint: %v", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// Stop stops updating the resource coverage tree, once the resources already
+// queued have been recorded. Callers should Checkpoint after Stop returns, so
+// that none of the resources received before it are lost.
+func (a *APICoverageRecorder) Stop() {
+	a.Logger.Info("APICoverageRecorder.Stop")
+	close(a.stopUpdates)
+	<-a.updatesDone
+}
+
+// updateResourceCoverageTree updates the resource coverage tree, until Stop is called.
 func (a *APICoverageRecorder) updateResourceCoverageTree() {
 	a.Logger.Info("APICoverageRecorder.updateResourceCoverageTree")
+	defer close(a.updatesDone)
 	for {
-		channelMsg := <-a.resourceChannel
-		a.Logger.Info("APICoverageRecorder.updateResourceCoverageTree received message")
-		resourceType := a.ResourceMap[channelMsg.resourceGVK]
-		resource := reflect.New(resourceType).Interface()
-		if err := json.Unmarshal(channelMsg.rawResourceValue, resource); err != nil {
-			a.Logger.Errorf("Failed unmarshalling review.Request.Object.Raw for type: %s Error: %v", channelMsg.resourceGVK.Kind, err)
-			continue
+		select {
+		case channelMsg := <-a.resourceChannel:
+			a.Logger.Info("APICoverageRecorder.updateResourceCoverageTree received message")
+			a.updateResourceCoverage(channelMsg)
+		case <-a.stopUpdates:
+			// Record whatever is still queued
+			for {
+				select {
+				case channelMsg := <-a.resourceChannel:
+					a.updateResourceCoverage(channelMsg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// updateResourceCoverage decodes a single resource and updates its resource tree.
+func (a *APICoverageRecorder) updateResourceCoverage(channelMsg resourceChannelMsg) {
+	key := ResourceKey(channelMsg.resourceGVK)
+	// Schema backed trees have no resourceType, and are updated from the
+	// unstructured object
+	resourceType, ok := a.ResourceMap[channelMsg.resourceGVK]
+	if !ok {
+		resourceType = a.OptionsMap[channelMsg.resourceGVK]
+	}
+	if channelMsg.subresource != nil {
+		key = SubresourceKey(*channelMsg.subresource)
+		resourceType = a.SubresourceMap[*channelMsg.subresource]
+	}
+
+	resource, resourceValue, err := decodeResource(channelMsg.rawResourceValue, resourceType)
+	if err != nil {
+		a.Logger.Errorf("Failed unmarshalling review.Request.Object.Raw for type: %s Error: %v", channelMsg.resourceGVK.Kind, err)
+		return
+	}
+	request := resourcetree.RequestInfo{
+		Test:      testName(resource, channelMsg),
+		Operation: channelMsg.operation,
+		Time:      channelMsg.timestamp,
+		Namespace: channelMsg.namespace,
+		Name:      channelMsg.name,
+		UID:       channelMsg.uid,
+	}
+	if accessor, err := meta.Accessor(resource); err == nil && len(accessor.GetName()) != 0 {
+		request.Namespace, request.Name, request.UID = accessor.GetNamespace(), accessor.GetName(), string(accessor.GetUID())
+	}
+	// The typed resource can't tell fields set to their zero value from
+	// absent ones, so presence is recorded from the unstructured resource
+	if resourceType == nil {
+		request.RawObject = resourceValue
+	} else if _, rawValue, err := decodeResource(channelMsg.rawResourceValue, nil); err == nil {
+		request.RawObject = rawValue
+	}
+	if len(channelMsg.rawOldResourceValue) != 0 {
+		if _, oldResourceValue, err := decodeResource(channelMsg.rawOldResourceValue, resourceType); err != nil {
+			a.Logger.Errorf("Failed unmarshalling review.Request.OldObject.Raw for type: %s Error: %v", channelMsg.resourceGVK.Kind, err)
+		} else {
+			request.OldObject = oldResourceValue
+		}
+	}
+	resourceTree := a.ResourceForest.TopLevelTrees[key]
+	resourceTree.UpdateCoverageFromRequest(resourceValue, request)
+	a.Logger.Infof("Successfully recorded coverage for resource %s from test %q", key, request.Test)
+}
+
+// decodeResource decodes a raw resource into a new value of resourceType, or
+// into an unstructured.Unstructured if resourceType is nil. It returns the
+// resource, and the value its resource tree is updated from.
+func decodeResource(raw []byte, resourceType reflect.Type) (interface{}, reflect.Value, error) {
+	if resourceType == nil {
+		unstructuredResource := &unstructured.Unstructured{}
+		if err := json.Unmarshal(raw, &unstructuredResource.Object); err != nil {
+			return nil, reflect.Value{}, err
+		}
+		return unstructuredResource, reflect.ValueOf(unstructuredResource.Object), nil
+	}
+	resource := reflect.New(resourceType).Interface()
+	if err := json.Unmarshal(raw, resource); err != nil {
+		return nil, reflect.Value{}, err
+	}
+	return resource, reflect.ValueOf(resource).Elem(), nil
+}
+
+// testName returns the name of the test that sent a resource. In order of
+// preference this is the TestNameAnnotation on the resource, the test name
+// the e2e framework appends to its user agent, or the username.
+func testName(resource interface{}, channelMsg resourceChannelMsg) string {
+	if accessor, err := meta.Accessor(resource); err == nil {
+		if test, ok := accessor.GetAnnotations()[TestNameAnnotation]; ok {
+			return test
 		}
-		resourceTree := a.ResourceForest.TopLevelTrees[channelMsg.resourceGVK.Kind]
-		resourceTree.UpdateCoverage(reflect.ValueOf(resource).Elem())
-		a.Logger.Info("Successfully recorded coverage for resource ", channelMsg.resourceGVK.Kind)
 	}
+	if i := strings.Index(channelMsg.userAgent, userAgentTestSeparator); i >= 0 {
+		return channelMsg.userAgent[i+len(userAgentTestSeparator):]
+	}
+	return channelMsg.username
 }
 
 // RecordResourceCoverage updates the resource tree with the request.
 func (a *APICoverageRecorder) RecordResourceCoverage(w http.ResponseWriter, r *http.Request) {
 	a.Logger.Info("APICoverageRecorder.RecordResourceCoverage")
 
-	review := &v1beta1.AdmissionReview{}
+	// admission.k8s.io v1 and v1beta1 AdmissionReviews share the same
+	// schema, so we decode either into v1 and keep the version we were sent.
+	review := &admissionv1.AdmissionReview{}
 	err := a.jsonRead(r, review, "review")
-	if err != nil {
+	if err == nil && !admissionReviewGroupVersions.Has(review.APIVersion) {
+		err = fmt.Errorf("unsupported AdmissionReview version %q", review.APIVersion)
+		a.Logger.Error(err)
+	}
+	if err != nil || review.Request == nil {
 		a.appendAndWriteAdmissionResponse(review, false, "Admission Denied", w)
 		return
 	}
@@ -130,25 +416,124 @@ func (a *APICoverageRecorder) RecordResourceCoverage(w http.ResponseWriter, r *h
 	}
 	op := review.Request.Operation
 	raw := review.Request.Object.Raw
+	if optionsMsg, ok := a.admissionOptionsMsg(review.Request); ok {
+		a.Logger.Infof("APICoverageRecorder.RecordResourceCoverage sending to channel options %v, op %v, raw %s", optionsMsg.resourceGVK, op, string(optionsMsg.rawResourceValue))
+		a.resourceChannel <- optionsMsg
+	}
+	// DELETE requests only send options
+	if len(raw) == 0 {
+		a.appendAndWriteAdmissionResponse(review, true, "Welcome Aboard", w)
+		return
+	}
+	subresource, err := a.admissionSubresource(review.Request)
+	if err != nil {
+		a.Logger.Infof("By-passing resource coverage update for resource %s: %v", gvk.Kind, err)
+		a.appendAndWriteAdmissionResponse(review, true, "Welcome Aboard", w)
+		return
+	}
 
 	// We only care about resources the repo has setup.
-	if _, ok := a.ResourceMap[gvk]; !ok {
+	if subresource == nil && !a.isSetup(gvk) {
 		a.Logger.Info("By-passing resource coverage update for resource : %s", gvk.Kind)
 		a.appendAndWriteAdmissionResponse(review, true, "Welcome Aboard", w)
 		return
 	}
-	a.Logger.Infof("APICoverageRecorder.RecordResourceCoverage sending to channel gvk %v, op %v, raw %s", gvk, op, string(raw))
+	a.Logger.Infof("APICoverageRecorder.RecordResourceCoverage sending to channel gvk %v, subresource %v, op %v, raw %s", gvk, subresource, op, string(raw))
 
-	a.resourceChannel <- resourceChannelMsg{
+	msg := resourceChannelMsg{
 		resourceGVK:      gvk,
+		subresource:      subresource,
 		rawResourceValue: raw,
+		username:         review.Request.UserInfo.Username,
+		operation:        string(op),
+		timestamp:        time.Now(),
+		namespace:        review.Request.Namespace,
+		name:             review.Request.Name,
 	}
+	// OldObject is also set for DELETE, where it is what's being deleted
+	if op == admissionv1.Update {
+		msg.rawOldResourceValue = review.Request.OldObject.Raw
+	}
+	a.resourceChannel <- msg
 	a.appendAndWriteAdmissionResponse(review, true, "Welcome Aboard", w)
 }
 
+// admissionOptionsMsg builds the resource channel message for the options of
+// an AdmissionRequest, if they are setup in OptionsMap. Older API servers
+// don't send options, only whether the request is a dry run, in which case
+// dry run options are recorded.
+func (a *APICoverageRecorder) admissionOptionsMsg(request *admissionv1.AdmissionRequest) (resourceChannelMsg, bool) {
+	raw := request.Options.Raw
+	gvk := v1.SchemeGroupVersion.WithKind(operationOptionsKinds[request.Operation])
+	if len(raw) == 0 {
+		if request.DryRun == nil || !*request.DryRun {
+			return resourceChannelMsg{}, false
+		}
+		raw = []byte(`{"dryRun": ["` + v1.DryRunAll + `"]}`)
+	} else if rawGVK, err := rawObjectGVK(raw); err == nil {
+		gvk = rawGVK
+	}
+	optionsGVK, ok := a.optionsGVK(gvk)
+	if !ok {
+		return resourceChannelMsg{}, false
+	}
+	return resourceChannelMsg{
+		resourceGVK:      optionsGVK,
+		rawResourceValue: raw,
+		username:         request.UserInfo.Username,
+		operation:        string(request.Operation),
+		timestamp:        time.Now(),
+		namespace:        request.Namespace,
+		name:             request.Name,
+	}, true
+}
+
+// admissionSubresource returns the subresource an AdmissionRequest was made
+// to, if it is setup in SubresourceMap. Requests to other subresources, eg:
+// pods/status, send the resource itself so are recorded as the resource.
+func (a *APICoverageRecorder) admissionSubresource(request *admissionv1.AdmissionRequest) (*Subresource, error) {
+	// Request* describe the request as it was made, before any conversion by
+	// the API server; since we register with matchPolicy Exact these should
+	// match Resource, SubResource and Kind, and may be unset by older servers
+	resource, subresourceName, kind := request.Resource, request.SubResource, request.Kind
+	if request.RequestResource != nil {
+		resource, subresourceName = *request.RequestResource, request.RequestSubResource
+	}
+	if request.RequestKind != nil {
+		kind = *request.RequestKind
+	}
+	subresource := Subresource{
+		Resource:    schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource},
+		Subresource: subresourceName,
+	}
+	bodyType, ok := a.SubresourceMap[subresource]
+	if !ok {
+		return nil, nil
+	}
+	if kind != request.Kind {
+		return nil, fmt.Errorf("%s was converted from %s to %s", SubresourceKey(subresource), kind.Kind, request.Kind.Kind)
+	}
+	if request.Kind.Kind != bodyType.Name() {
+		return nil, fmt.Errorf("%s was sent %s, expected %s", SubresourceKey(subresource), request.Kind.Kind, bodyType.Name())
+	}
+	return &subresource, nil
+}
+
 // TODO(spiffxp): do we have to keep the request on the review object?
-func (a *APICoverageRecorder) appendAndWriteAdmissionResponse(review *v1beta1.AdmissionReview, allowed bool, message string, w http.ResponseWriter) {
-	review.Response = &v1beta1.AdmissionResponse{
+// appendAndWriteAdmissionResponse responds to review in the version it was
+// sent in, defaulting to v1beta1 for requests we couldn't decode.
+func (a *APICoverageRecorder) appendAndWriteAdmissionResponse(review *admissionv1.AdmissionReview, allowed bool, message string, w http.ResponseWriter) {
+	if !admissionReviewGroupVersions.Has(review.APIVersion) {
+		review.APIVersion = admissionv1beta1.SchemeGroupVersion.String()
+	}
+	review.Kind = "AdmissionReview"
+	var uid types.UID
+	if review.Request != nil {
+		uid = review.Request.UID
+	}
+	review.Request = nil
+	review.Response = &admissionv1.AdmissionResponse{
+		UID:     uid,
 		Allowed: allowed,
 		Result: &v1.Status{
 			Message: message,
@@ -157,25 +542,269 @@ func (a *APICoverageRecorder) appendAndWriteAdmissionResponse(review *v1beta1.Ad
 	a.jsonWrite(w, review, "review response")
 }
 
-// getCoverage returns the CoverageValues and TypeCoverage for a given kind
-func (a *APICoverageRecorder) getCoverage(kind string) (coveragecalculator.CoverageValues, []coveragecalculator.TypeCoverage) {
-	tree := a.ResourceForest.TopLevelTrees[kind]
-	typeCoverage := tree.BuildCoverageData(a.NodeRules, a.FieldRules, a.ignoredFields)
+// isSetup returns whether coverage of the resource for a given GVK is recorded.
+func (a *APICoverageRecorder) isSetup(gvk schema.GroupVersionKind) bool {
+	_, ok := a.ResourceForest.TopLevelTrees[ResourceKey(gvk)]
+	return ok
+}
+
+// optionsGVK returns the GVK options of a given GVK are setup as in
+// OptionsMap. Options are registered in every group version, eg: as both
+// v1/DeleteOptions and meta.k8s.io/v1/DeleteOptions, so are matched by kind.
+func (a *APICoverageRecorder) optionsGVK(gvk schema.GroupVersionKind) (schema.GroupVersionKind, bool) {
+	for optionsGVK := range a.OptionsMap {
+		if optionsGVK.Kind == gvk.Kind {
+			return optionsGVK, true
+		}
+	}
+	return schema.GroupVersionKind{}, false
+}
+
+// isOptionsKey returns whether a tree key is that of options.
+func (a *APICoverageRecorder) isOptionsKey(key string) bool {
+	_, ok := a.OptionsMap[ParseResourceKey(key)]
+	return ok
+}
+
+// isSubresourceKey returns whether a tree key is that of a subresource.
+func (a *APICoverageRecorder) isSubresourceKey(key string) bool {
+	subresource, ok := ParseSubresourceKey(key)
+	if !ok {
+		return false
+	}
+	_, ok = a.SubresourceMap[subresource]
+	return ok
+}
+
+// ResourceKey returns the key of the resource tree for a given GVK, which is
+// its apiVersion and kind, eg: v1/Pod or apps/v1/Deployment.
+func ResourceKey(gvk schema.GroupVersionKind) string {
+	return gvk.GroupVersion().String() + "/" + gvk.Kind
+}
+
+// ParseResourceKey returns the GVK of a ResourceKey.
+func ParseResourceKey(key string) schema.GroupVersionKind {
+	i := strings.LastIndex(key, "/")
+	if i < 0 {
+		return schema.GroupVersionKind{Kind: key}
+	}
+	return schema.FromAPIVersionAndKind(key[:i], key[i+1:])
+}
+
+// Subresource identifies a subresource of a resource, eg: exec of v1 pods.
+type Subresource struct {
+	Resource    schema.GroupVersionResource
+	Subresource string
+}
+
+// SubresourceKey returns the key of the resource tree for a given
+// subresource, which is its resource's apiVersion, resource and subresource,
+// eg: v1/pods/exec or apps/v1/deployments/scale.
+func SubresourceKey(subresource Subresource) string {
+	return subresource.Resource.GroupVersion().String() + "/" + subresource.Resource.Resource + "/" + subresource.Subresource
+}
+
+// ParseSubresourceKey returns the Subresource of a SubresourceKey, or false
+// if key is not one.
+func ParseSubresourceKey(key string) (Subresource, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) < 3 || len(parts) > 4 {
+		return Subresource{}, false
+	}
+	n := len(parts)
+	gv, err := schema.ParseGroupVersion(strings.Join(parts[:n-2], "/"))
+	if err != nil {
+		return Subresource{}, false
+	}
+	return Subresource{Resource: gv.WithResource(parts[n-2]), Subresource: parts[n-1]}, true
+}
+
+// ResolveResourceKey returns the ResourceKey of the one resource tree matching
+// resource, which is either a ResourceKey or a suffix of one that starts after
+// a "/", eg: Deployment, or v1/Deployment for apps/v1/Deployment.
+func (a *APICoverageRecorder) ResolveResourceKey(resource string) (string, error) {
+	if _, ok := a.ResourceForest.TopLevelTrees[resource]; ok {
+		return resource, nil
+	}
+	candidates := []string{}
+	for key := range a.ResourceForest.TopLevelTrees {
+		if strings.HasSuffix(key, "/"+resource) {
+			candidates = append(candidates, key)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("Resource information not found for resource: %s", resource)
+	case 1:
+		return candidates[0], nil
+	}
+	sort.Strings(candidates)
+	return "", fmt.Errorf("Resource %s is ambiguous, use one of: %s", resource, strings.Join(candidates, ", "))
+}
+
+// BuildResourceCoverage returns the CoverageValues and TypeCoverage for a given ResourceKey
+func (a *APICoverageRecorder) BuildResourceCoverage(key string, options resourcetree.CoverageOptions) (coveragecalculator.CoverageValues, []coveragecalculator.TypeCoverage) {
+	options.AllTypes = options.AllTypes || a.AllTypes
+	tree := a.ResourceForest.TopLevelTrees[key]
+	typeCoverage := tree.BuildCoverageData(a.NodeRules, a.FieldRules, a.ignoredFields, options)
+	coverageValues := coveragecalculator.CalculateTypeCoverage(typeCoverage)
+	return coverageValues, typeCoverage
+}
+
+// BuildResourcePathCoverage returns the CoverageValues and TypeCoverage for a
+// given ResourceKey, outlined per path from the resource instead of per type.
+func (a *APICoverageRecorder) BuildResourcePathCoverage(key string, options resourcetree.CoverageOptions) (coveragecalculator.CoverageValues, []coveragecalculator.TypeCoverage) {
+	options.AllTypes = options.AllTypes || a.AllTypes
+	tree := a.ResourceForest.TopLevelTrees[key]
+	typeCoverage := tree.BuildPathCoverageData(a.NodeRules, a.FieldRules, a.ignoredFields, options)
 	coverageValues := coveragecalculator.CalculateTypeCoverage(typeCoverage)
 	return coverageValues, typeCoverage
 }
 
-// GetResourceCoverage retrieves resource coverage data for the passed in resource via query param.
+// BuildTestCoverage returns the CoverageValues and TypeCoverage of the fields
+// covered by a given test, across all the resources it sent.
+func (a *APICoverageRecorder) BuildTestCoverage(test string) (coveragecalculator.CoverageValues, []coveragecalculator.TypeCoverage) {
+	options := resourcetree.CoverageOptions{Test: test, AllTypes: a.AllTypes}
+	typeCoverage := []coveragecalculator.TypeCoverage{}
+	// Types are outlined across all trees via ConnectedNodes, so a type
+	// reachable from more than one resource only needs to be listed once.
+	seenTypes := sets.String{}
+	for _, tree := range a.ResourceForest.TopLevelTrees {
+		for _, coverage := range tree.BuildCoverageData(a.NodeRules, a.FieldRules, a.ignoredFields, options) {
+			if !seenTypes.Has(coverage.Package + "." + coverage.Type) {
+				seenTypes.Insert(coverage.Package + "." + coverage.Type)
+				typeCoverage = append(typeCoverage, coverage)
+			}
+		}
+	}
+	return coveragecalculator.CalculateTypeCoverage(typeCoverage), typeCoverage
+}
+
+// BuildTotalCoverage returns the coverage values accumulated over all the
+// resources setup for the apicoverage tool, not including subresources or options.
+func (a *APICoverageRecorder) BuildTotalCoverage(options resourcetree.CoverageOptions) coveragecalculator.CoverageValues {
+	totalCoverage := coveragecalculator.CoverageValues{}
+	for key := range a.ResourceForest.TopLevelTrees {
+		if a.isSubresourceKey(key) || a.isOptionsKey(key) {
+			continue
+		}
+		coverageValues, _ := a.BuildResourceCoverage(key, options)
+		totalCoverage.Accumulate(coverageValues)
+	}
+	return totalCoverage
+}
+
+// BuildResourceCoveragePercentages returns percentage coverage for each
+// resource setup for the apicoverage tool, along with the "Overall" percentage
+// of resources, and for each subresource and options.
+func (a *APICoverageRecorder) BuildResourceCoveragePercentages() coveragecalculator.CoveragePercentages {
+	percentCoverages := make(map[string]float64)
+	subresourcePercentCoverages := make(map[string]float64)
+	optionsPercentCoverages := make(map[string]float64)
+	totalCoverage := coveragecalculator.CoverageValues{}
+	for key := range a.ResourceForest.TopLevelTrees {
+		coverageValues, _ := a.BuildResourceCoverage(key, resourcetree.CoverageOptions{})
+		if a.isSubresourceKey(key) {
+			subresourcePercentCoverages[key] = coverageValues.PercentCoverage
+			continue
+		}
+		if a.isOptionsKey(key) {
+			optionsPercentCoverages[key] = coverageValues.PercentCoverage
+			continue
+		}
+		percentCoverages[key] = coverageValues.PercentCoverage
+		totalCoverage.Accumulate(coverageValues)
+	}
+	percentCoverages["Overall"] = totalCoverage.PercentCoverage
+	return coveragecalculator.CoveragePercentages{
+		ResourceCoverages:    percentCoverages,
+		SubresourceCoverages: subresourcePercentCoverages,
+		OptionsCoverages:     optionsPercentCoverages,
+	}
+}
+
+// coverageOptions returns the CoverageOptions selected by a request's query params.
+func coverageOptions(r *http.Request) (resourcetree.CoverageOptions, error) {
+	options := resourcetree.CoverageOptions{}
+	if operation := r.URL.Query().Get(OperationQueryParam); len(operation) != 0 {
+		options.Operation = strings.ToUpper(operation)
+		if !operations.Has(options.Operation) {
+			return options, fmt.Errorf("Unknown operation %s, use one of: %s", operation, strings.Join(operations.List(), ", "))
+		}
+	}
+	if mutated := r.URL.Query().Get(MutatedQueryParam); len(mutated) != 0 {
+		var err error
+		if options.Mutated, err = strconv.ParseBool(mutated); err != nil {
+			return options, fmt.Errorf("Invalid %s query param %q: %v", MutatedQueryParam, mutated, err)
+		}
+	}
+	if allTypes := r.URL.Query().Get(AllTypesQueryParam); len(allTypes) != 0 {
+		var err error
+		if options.AllTypes, err = strconv.ParseBool(allTypes); err != nil {
+			return options, fmt.Errorf("Invalid %s query param %q: %v", AllTypesQueryParam, allTypes, err)
+		}
+	}
+	return options, nil
+}
+
+// GetResourceCoverage retrieves resource coverage data for the passed in
+// resource via query param, optionally only of the passed in operation.
 func (a *APICoverageRecorder) GetResourceCoverage(w http.ResponseWriter, r *http.Request) {
 	a.Logger.Infof("APICoverageRecorder.GetResourceCoverage")
+	a.writeResourceCoverage(w, r, a.BuildResourceCoverage)
+}
+
+// GetResourcePathCoverage retrieves resource coverage data like
+// GetResourceCoverage, outlined per path from the resource instead of per type.
+func (a *APICoverageRecorder) GetResourcePathCoverage(w http.ResponseWriter, r *http.Request) {
+	a.Logger.Infof("APICoverageRecorder.GetResourcePathCoverage")
+	a.writeResourceCoverage(w, r, a.BuildResourcePathCoverage)
+}
+
+// writeResourceCoverage writes the coverage buildCoverage builds for the
+// resource and options passed in via query params, as HTML or JSON.
+func (a *APICoverageRecorder) writeResourceCoverage(w http.ResponseWriter, r *http.Request,
+	buildCoverage func(string, resourcetree.CoverageOptions) (coveragecalculator.CoverageValues, []coveragecalculator.TypeCoverage)) {
+	key, err := a.ResolveResourceKey(r.URL.Query().Get(ResourceQueryParam))
+	if err != nil {
+		fmt.Fprint(w, err.Error())
+		return
+	}
+	options, err := coverageOptions(r)
+	if err != nil {
+		fmt.Fprint(w, err.Error())
+		return
+	}
 
-	resource := r.URL.Query().Get(ResourceQueryParam)
-	if _, ok := a.ResourceForest.TopLevelTrees[resource]; !ok {
-		fmt.Fprintf(w, "Resource information not found for resource: %s", resource)
+	coverageValues, typeCoverage := buildCoverage(key, options)
+	if r.URL.Query().Get(FormatQueryParam) == formatJSON {
+		a.jsonWrite(w, typeCoverage, "resource coverage")
 		return
 	}
 
-	coverageValues, typeCoverage := a.getCoverage(resource)
+	if htmlData, err := view.GetHTMLDisplay(typeCoverage, coverageValues); err != nil {
+		fmt.Fprintf(w, "Error generating html file %v", err)
+	} else {
+		fmt.Fprint(w, htmlData)
+	}
+}
+
+// GetTestCoverage retrieves coverage data for the fields covered by the test
+// passed in via query param, or lists all tests if none is passed in.
+func (a *APICoverageRecorder) GetTestCoverage(w http.ResponseWriter, r *http.Request) {
+	a.Logger.Infof("APICoverageRecorder.GetTestCoverage")
+
+	test := r.URL.Query().Get(TestQueryParam)
+	if len(test) == 0 {
+		a.jsonWrite(w, a.ResourceForest.Tests().List(), "tests")
+		return
+	}
+
+	coverageValues, typeCoverage := a.BuildTestCoverage(test)
+	if len(typeCoverage) == 0 {
+		fmt.Fprintf(w, "Coverage information not found for test: %s", test)
+		return
+	}
 
 	if htmlData, err := view.GetHTMLDisplay(typeCoverage, coverageValues); err != nil {
 		fmt.Fprintf(w, "Error generating html file %v", err)
@@ -184,17 +813,18 @@ func (a *APICoverageRecorder) GetResourceCoverage(w http.ResponseWriter, r *http
 	}
 }
 
-// GetTotalCoverage goes over all the resources setup for the apicoverage tool and returns total coverage values.
+// GetTotalCoverage goes over all the resources setup for the apicoverage tool
+// and returns total coverage values, optionally only of the operation passed
+// in via query param.
 func (a *APICoverageRecorder) GetTotalCoverage(w http.ResponseWriter, r *http.Request) {
 	a.Logger.Infof("APICoverageRecorder.GetTotalCoverage")
 
-	totalCoverage := coveragecalculator.CoverageValues{}
-	for resource := range a.ResourceMap {
-		coverageValues, _ := a.getCoverage(resource.Kind)
-		totalCoverage.Accumulate(coverageValues)
+	options, err := coverageOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	a.jsonWrite(w, totalCoverage, "total coverage")
+	a.jsonWrite(w, a.BuildTotalCoverage(options), "total coverage")
 }
 
 // GetResourceCoveragePercentages goes over all the resources setup for the
@@ -202,16 +832,7 @@ func (a *APICoverageRecorder) GetTotalCoverage(w http.ResponseWriter, r *http.Re
 func (a *APICoverageRecorder) GetResourceCoveragePercentages(w http.ResponseWriter, r *http.Request) {
 	a.Logger.Infof("APICoverageRecorder.GetResourceCoveragePercentages")
 
-	percentCoverages := make(map[string]float64)
-	totalCoverage := coveragecalculator.CoverageValues{}
-	for resource := range a.ResourceMap {
-		coverageValues, _ := a.getCoverage(resource.Kind)
-		percentCoverages[resource.Kind] = coverageValues.PercentCoverage
-		totalCoverage.Accumulate(coverageValues)
-	}
-	percentCoverages["Overall"] = totalCoverage.PercentCoverage
-
-	a.jsonWrite(w, coveragecalculator.CoveragePercentages{ResourceCoverages: percentCoverages}, "percent coverage")
+	a.jsonWrite(w, a.BuildResourceCoveragePercentages(), "percent coverage")
 }
 
 func (a *APICoverageRecorder) jsonRead(r *http.Request, obj runtime.Object, description string) error {
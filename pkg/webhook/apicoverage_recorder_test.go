@@ -0,0 +1,563 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+	"sigs.k8s.io/k8s-api-coverage/pkg/coveragecalculator"
+	"sigs.k8s.io/k8s-api-coverage/pkg/resourcetree"
+)
+
+const podReviewTemplate = `{
+	"apiVersion": "admission.k8s.io/VERSION",
+	"kind": "AdmissionReview",
+	"request": {
+		"uid": "review-uid",
+		"kind": {"group": "", "version": "v1", "kind": "Pod"},
+		"operation": "CREATE",
+		"userInfo": {"username": "test-user"},
+		"object": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}}
+	}
+}`
+
+func TestRecordResourceCoverageVersions(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	for _, version := range []string{"v1", "v1beta1"} {
+		t.Run(version, func(t *testing.T) {
+			a := &APICoverageRecorder{
+				Logger: zap.NewNop().Sugar(),
+				ResourceForest: resourcetree.ResourceForest{
+					TopLevelTrees: map[string]resourcetree.ResourceTree{ResourceKey(podGVK): {}},
+				},
+				resourceChannel: make(chan resourceChannelMsg, 1),
+			}
+			r := httptest.NewRequest("POST", "/", strings.NewReader(strings.Replace(podReviewTemplate, "VERSION", version, 1)))
+			w := httptest.NewRecorder()
+			a.RecordResourceCoverage(w, r)
+
+			review := &admissionv1.AdmissionReview{}
+			if err := json.Unmarshal(w.Body.Bytes(), review); err != nil {
+				t.Fatalf("Unable to decode response %q: %v", w.Body.String(), err)
+			}
+			if expected := "admission.k8s.io/" + version; review.APIVersion != expected {
+				t.Errorf("Expected response apiVersion %s, found: %s", expected, review.APIVersion)
+			}
+			if review.Response == nil || !review.Response.Allowed || review.Response.UID != "review-uid" {
+				t.Errorf("Expected an allowed response for uid review-uid, found: %+v", review.Response)
+			}
+			if msg := <-a.resourceChannel; msg.resourceGVK != podGVK || msg.username != "test-user" || msg.operation != "CREATE" {
+				t.Errorf("Expected coverage update for %v by test-user on CREATE, found: %+v", podGVK, msg)
+			}
+		})
+	}
+}
+
+const podExecReview = `{
+	"apiVersion": "admission.k8s.io/v1",
+	"kind": "AdmissionReview",
+	"request": {
+		"uid": "review-uid",
+		"kind": {"group": "", "version": "v1", "kind": "PodExecOptions"},
+		"resource": {"group": "", "version": "v1", "resource": "pods"},
+		"subResource": "exec",
+		"requestKind": {"group": "", "version": "v1", "kind": "PodExecOptions"},
+		"requestResource": {"group": "", "version": "v1", "resource": "pods"},
+		"requestSubResource": "exec",
+		"operation": "CONNECT",
+		"userInfo": {"username": "test-user"},
+		"object": {"apiVersion": "v1", "kind": "PodExecOptions", "stdout": true, "command": ["ls"]}
+	}
+}`
+
+func TestRecordSubresourceCoverage(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	podExec := Subresource{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "exec"}
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap:     map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+		SubresourceMap:  map[Subresource]reflect.Type{podExec: reflect.TypeOf(corev1.PodExecOptions{})},
+		resourceChannel: make(chan resourceChannelMsg, 1),
+	}
+	a.ResourceForest.AddResourceTree(ResourceKey(podGVK), podGVK.Kind, a.ResourceMap[podGVK])
+	a.ResourceForest.AddResourceTree(SubresourceKey(podExec), "PodExecOptions", a.SubresourceMap[podExec])
+
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(podExecReview)))
+	msg := <-a.resourceChannel
+	if msg.subresource == nil || *msg.subresource != podExec {
+		t.Fatalf("Expected coverage update for %s, found: %+v", SubresourceKey(podExec), msg)
+	}
+	a.updateResourceCoverage(msg)
+
+	percentages := a.BuildResourceCoveragePercentages()
+	if coverage, ok := percentages.SubresourceCoverages["v1/pods/exec"]; !ok || coverage == 0 {
+		t.Errorf("Expected coverage of v1/pods/exec, found: %v", percentages.SubresourceCoverages)
+	}
+	if len(percentages.ResourceCoverages) != 2 || percentages.ResourceCoverages["v1/Pod"] != 0 {
+		t.Errorf("Expected no coverage of v1/Pod, and no subresources, found: %v", percentages.ResourceCoverages)
+	}
+	if parsed, ok := ParseSubresourceKey(SubresourceKey(podExec)); !ok || parsed != podExec {
+		t.Errorf("Expected %s to parse back to %v, found: %v", SubresourceKey(podExec), podExec, parsed)
+	}
+
+	// Bodies of subresources that aren't setup are the resource itself
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(
+		strings.Replace(strings.Replace(podReviewTemplate, "VERSION", "v1", 1), `"operation"`, `"resource": {"group": "", "version": "v1", "resource": "pods"}, "subResource": "status", "operation"`, 1))))
+	if msg := <-a.resourceChannel; msg.subresource != nil || msg.resourceGVK != podGVK {
+		t.Errorf("Expected coverage update for %v, found: %+v", podGVK, msg)
+	}
+}
+
+func TestRecordResourceCoverageMutation(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap:     map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+		resourceChannel: make(chan resourceChannelMsg, 1),
+	}
+	a.ResourceForest.AddResourceTree(ResourceKey(podGVK), podGVK.Kind, a.ResourceMap[podGVK])
+
+	review := strings.NewReplacer(
+		"VERSION", "v1",
+		`"CREATE"`, `"UPDATE"`,
+		`"metadata": {"name": "test"}}`, `"metadata": {"name": "test"}, "spec": {"nodeName": "node-b"}}, "oldObject": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}, "spec": {"nodeName": "node-a"}}`,
+	).Replace(podReviewTemplate)
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(review)))
+	msg := <-a.resourceChannel
+	if msg.operation != "UPDATE" || len(msg.rawOldResourceValue) == 0 {
+		t.Fatalf("Expected coverage update on UPDATE with the old object, found: %+v", msg)
+	}
+	a.updateResourceCoverage(msg)
+
+	_, typeCoverage := a.BuildResourceCoverage(ResourceKey(podGVK), resourcetree.CoverageOptions{Mutated: true})
+	for _, coverage := range typeCoverage {
+		if coverage.Type != "PodSpec" {
+			continue
+		}
+		for field, fieldCoverage := range coverage.Fields {
+			if fieldCoverage.Mutated != (field == "nodeName") {
+				t.Errorf("Expected only PodSpec.nodeName mutated, found %s: %+v", field, fieldCoverage)
+			}
+		}
+		if exemplars := coverage.Fields["nodeName"].Exemplars; len(exemplars) != 1 || exemplars[0].Name != "test" || exemplars[0].Operation != "UPDATE" || exemplars[0].Time.IsZero() {
+			t.Errorf("Expected PodSpec.nodeName covered by an UPDATE of test, found: %+v", exemplars)
+		}
+		return
+	}
+	t.Errorf("Expected PodSpec coverage of mutated fields, found: %+v", typeCoverage)
+}
+
+const podDeleteReview = `{
+	"apiVersion": "admission.k8s.io/v1",
+	"kind": "AdmissionReview",
+	"request": {
+		"uid": "review-uid",
+		"kind": {"group": "", "version": "v1", "kind": "Pod"},
+		"namespace": "default",
+		"name": "test",
+		"operation": "DELETE",
+		"userInfo": {"username": "test-user"},
+		"oldObject": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}},
+		"dryRun": true,
+		"options": {"apiVersion": "meta.k8s.io/v1", "kind": "DeleteOptions", "gracePeriodSeconds": 0, "propagationPolicy": "Foreground", "dryRun": ["All"]}
+	}
+}`
+
+func TestRecordOptionsCoverage(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	createOptionsGVK := metav1.SchemeGroupVersion.WithKind("CreateOptions")
+	deleteOptionsGVK := metav1.SchemeGroupVersion.WithKind("DeleteOptions")
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap: map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+		OptionsMap: map[schema.GroupVersionKind]reflect.Type{
+			createOptionsGVK: reflect.TypeOf(metav1.CreateOptions{}),
+			deleteOptionsGVK: reflect.TypeOf(metav1.DeleteOptions{}),
+		},
+		resourceChannel: make(chan resourceChannelMsg, 2),
+	}
+	a.ResourceForest.AddResourceTree(ResourceKey(podGVK), podGVK.Kind, a.ResourceMap[podGVK])
+	for gvk, optionsType := range a.OptionsMap {
+		a.ResourceForest.AddResourceTree(ResourceKey(gvk), gvk.Kind, optionsType)
+	}
+
+	// Only the options of a delete are recorded
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(podDeleteReview)))
+	if len(a.resourceChannel) != 1 {
+		t.Fatalf("Expected 1 coverage update for a DELETE, found: %d", len(a.resourceChannel))
+	}
+	msg := <-a.resourceChannel
+	if msg.resourceGVK != deleteOptionsGVK || msg.operation != "DELETE" {
+		t.Fatalf("Expected coverage update for %v on DELETE, found: %+v", deleteOptionsGVK, msg)
+	}
+	a.updateResourceCoverage(msg)
+
+	_, typeCoverage := a.BuildResourceCoverage(ResourceKey(deleteOptionsGVK), resourcetree.CoverageOptions{})
+	for _, coverage := range typeCoverage {
+		if coverage.Type != "DeleteOptions" {
+			continue
+		}
+		for _, field := range []string{"propagationPolicy", "dryRun"} {
+			if !coverage.Fields[field].Coverage {
+				t.Errorf("Expected DeleteOptions.%s covered, found: %+v", field, coverage.Fields[field])
+			}
+		}
+		// Options have no metadata, so are attributed to the object deleted
+		if exemplars := coverage.Fields["propagationPolicy"].Exemplars; len(exemplars) != 1 || exemplars[0].Namespace != "default" || exemplars[0].Name != "test" {
+			t.Errorf("Expected DeleteOptions.propagationPolicy covered by a DELETE of default/test, found: %+v", exemplars)
+		}
+		if !coverage.Fields["gracePeriodSeconds"].Present || coverage.Fields["orphanDependents"].Present {
+			t.Errorf("Expected DeleteOptions.gracePeriodSeconds present, and orphanDependents not, found: %+v", coverage.Fields)
+		}
+	}
+
+	// Dry runs are recorded from older API servers, which don't send options
+	createReview := strings.Replace(strings.Replace(podReviewTemplate, "VERSION", "v1", 1), `"operation"`, `"dryRun": true, "operation"`, 1)
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(createReview)))
+	if msg := <-a.resourceChannel; msg.resourceGVK != createOptionsGVK || string(msg.rawResourceValue) != `{"dryRun": ["All"]}` {
+		t.Errorf("Expected dry run %v, found: %+v", createOptionsGVK, msg)
+	}
+	if msg := <-a.resourceChannel; msg.resourceGVK != podGVK {
+		t.Errorf("Expected coverage update for %v, found: %+v", podGVK, msg)
+	}
+
+	percentages := a.BuildResourceCoveragePercentages()
+	if coverage, ok := percentages.OptionsCoverages[ResourceKey(deleteOptionsGVK)]; !ok || coverage == 0 {
+		t.Errorf("Expected coverage of %s, found: %v", ResourceKey(deleteOptionsGVK), percentages.OptionsCoverages)
+	}
+	if len(percentages.ResourceCoverages) != 2 {
+		t.Errorf("Expected coverage of v1/Pod and Overall, and no options, found: %v", percentages.ResourceCoverages)
+	}
+}
+
+func TestGetResourceCoverageJSON(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap:     map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+		resourceChannel: make(chan resourceChannelMsg, 1),
+	}
+	a.ResourceForest.AddResourceTree(ResourceKey(podGVK), podGVK.Kind, a.ResourceMap[podGVK])
+
+	before := time.Now()
+	review := strings.Replace(strings.Replace(podReviewTemplate, "VERSION", "v1", 1),
+		`"metadata": {"name": "test"}`, `"metadata": {"namespace": "default", "name": "test", "uid": "pod-uid"}`, 1)
+	a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(review)))
+	a.updateResourceCoverage(<-a.resourceChannel)
+
+	for _, endpoint := range []string{ResourceCoverageEndPoint, ResourcePathCoverageEndPoint} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", endpoint+"?resource=Pod&format=json", nil)
+		if endpoint == ResourceCoverageEndPoint {
+			a.GetResourceCoverage(w, r)
+		} else {
+			a.GetResourcePathCoverage(w, r)
+		}
+		typeCoverage := []coveragecalculator.TypeCoverage{}
+		if err := json.Unmarshal(w.Body.Bytes(), &typeCoverage); err != nil {
+			t.Fatalf("%s: unable to decode response %q: %v", endpoint, w.Body.String(), err)
+		}
+		// Only the Pod itself has a metadata field
+		var exemplars []coveragecalculator.Exemplar
+		for _, coverage := range typeCoverage {
+			if metadata, ok := coverage.Fields["metadata"]; ok {
+				exemplars = metadata.Exemplars
+			}
+		}
+		if len(exemplars) != 1 {
+			t.Fatalf("%s: expected Pod.metadata covered by 1 exemplar, found: %+v", endpoint, exemplars)
+		}
+		if e := exemplars[0]; e.Namespace != "default" || e.Name != "test" || e.UID != "pod-uid" || e.Operation != "CREATE" || e.Time.Before(before.Truncate(time.Second)) {
+			t.Errorf("%s: expected a CREATE of default/test (pod-uid) after %v, found: %+v", endpoint, before, e)
+		}
+	}
+}
+
+func TestResolveResourceKey(t *testing.T) {
+	a := &APICoverageRecorder{
+		ResourceForest: resourcetree.ResourceForest{
+			TopLevelTrees: map[string]resourcetree.ResourceTree{},
+		},
+	}
+	for _, gvk := range []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Pod"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "Scale"},
+		{Group: "autoscaling", Version: "v1", Kind: "Scale"},
+	} {
+		a.ResourceForest.TopLevelTrees[ResourceKey(gvk)] = resourcetree.ResourceTree{}
+		if parsed := ParseResourceKey(ResourceKey(gvk)); parsed != gvk {
+			t.Errorf("Expected %s to parse back to %v, found: %v", ResourceKey(gvk), gvk, parsed)
+		}
+	}
+
+	for resource, expected := range map[string]string{
+		"v1/Pod":             "v1/Pod",
+		"Pod":                "v1/Pod",
+		"Deployment":         "apps/v1/Deployment",
+		"v1/Deployment":      "apps/v1/Deployment",
+		"apps/v1/Deployment": "apps/v1/Deployment",
+		"apps/v1/Scale":      "apps/v1/Scale",
+		"Scale":              "",
+		"v1/Scale":           "",
+		"ployment":           "",
+		"Service":            "",
+	} {
+		key, err := a.ResolveResourceKey(resource)
+		if expected == "" && err == nil {
+			t.Errorf("Expected an error resolving %s, found key: %s", resource, key)
+		} else if expected != "" && key != expected {
+			t.Errorf("Expected %s to resolve to %s, found: %s, error: %v", resource, expected, key, err)
+		}
+	}
+}
+
+func TestCoverageOptions(t *testing.T) {
+	for query, expected := range map[string]string{
+		"":                  "",
+		"?mutated=true":     "",
+		"?alltypes=1":       "",
+		"?operation=UPDATE": "UPDATE",
+		"?operation=create": "CREATE",
+		"?operation=patch":  "error",
+		"?mutated=maybe":    "error",
+	} {
+		options, err := coverageOptions(httptest.NewRequest("GET", TotalCoverageEndPoint+query, nil))
+		if expected == "error" && err == nil {
+			t.Errorf("Expected an error for query %q, found options: %+v", query, options)
+		} else if expected != "error" && (err != nil || options.Operation != expected) {
+			t.Errorf("Expected operation %q for query %q, found: %q, error: %v", expected, query, options.Operation, err)
+		}
+		if options.Mutated != strings.Contains(query, "mutated=true") {
+			t.Errorf("Expected mutated %t for query %q, found: %t", !options.Mutated, query, options.Mutated)
+		}
+		if options.AllTypes != strings.Contains(query, "alltypes=1") {
+			t.Errorf("Expected alltypes %t for query %q, found: %t", !options.AllTypes, query, options.AllTypes)
+		}
+	}
+}
+
+func TestAuditEventMsg(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	deleteOptionsGVK := metav1.SchemeGroupVersion.WithKind("DeleteOptions")
+	podExec := Subresource{Resource: corev1.SchemeGroupVersion.WithResource("pods"), Subresource: "exec"}
+	a := &APICoverageRecorder{
+		ResourceForest: resourcetree.ResourceForest{
+			TopLevelTrees: map[string]resourcetree.ResourceTree{ResourceKey(podGVK): {}},
+		},
+		SubresourceMap: map[Subresource]reflect.Type{podExec: reflect.TypeOf(corev1.PodExecOptions{})},
+		OptionsMap:     map[schema.GroupVersionKind]reflect.Type{deleteOptionsGVK: reflect.TypeOf(metav1.DeleteOptions{})},
+	}
+	pod := &runtime.Unknown{Raw: []byte(`{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}}`)}
+	podRef := &auditv1.ObjectReference{Resource: "pods", Namespace: "default", Name: "test", APIVersion: "v1"}
+
+	datas := []struct {
+		name        string
+		event       auditv1.Event
+		skipped     bool
+		gvk         schema.GroupVersionKind
+		operation   string
+		subresource *Subresource
+		test        string
+	}{{
+		name:      "create",
+		event:     auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "create", ObjectRef: podRef, RequestObject: pod},
+		gvk:       podGVK,
+		operation: "CREATE",
+	}, {
+		name:    "request received stage",
+		event:   auditv1.Event{Stage: auditv1.StageRequestReceived, Verb: "create", ObjectRef: podRef, RequestObject: pod},
+		skipped: true,
+	}, {
+		name:    "patch",
+		event:   auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "patch", ObjectRef: podRef, RequestObject: pod},
+		skipped: true,
+	}, {
+		name:    "no request object",
+		event:   auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "create", ObjectRef: podRef},
+		skipped: true,
+	}, {
+		name: "delete options",
+		event: auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "delete", ObjectRef: podRef,
+			RequestObject: &runtime.Unknown{Raw: []byte(`{"apiVersion": "v1", "kind": "DeleteOptions", "propagationPolicy": "Foreground"}`)}},
+		gvk:       deleteOptionsGVK,
+		operation: "DELETE",
+	}, {
+		name: "pods/exec",
+		event: auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "create",
+			ObjectRef:     &auditv1.ObjectReference{Resource: "pods", Subresource: "exec", Namespace: "default", Name: "test", APIVersion: "v1"},
+			RequestObject: &runtime.Unknown{Raw: []byte(`{"apiVersion": "v1", "kind": "PodExecOptions", "command": ["ls"]}`)}},
+		gvk:         corev1.SchemeGroupVersion.WithKind("PodExecOptions"),
+		operation:   "CONNECT",
+		subresource: &podExec,
+	}, {
+		name: "kind not setup",
+		event: auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "create",
+			RequestObject: &runtime.Unknown{Raw: []byte(`{"apiVersion": "v1", "kind": "Service"}`)}},
+		skipped: true,
+	}, {
+		name: "user agent of an e2e test",
+		event: auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "update", ObjectRef: podRef, RequestObject: pod,
+			UserAgent: "e2e.test/v1.16.0 -- [sig-node] Pods should be updated", User: authenticationv1.UserInfo{Username: "test-user"}},
+		gvk:       podGVK,
+		operation: "UPDATE",
+		test:      "[sig-node] Pods should be updated",
+	}, {
+		name: "username",
+		event: auditv1.Event{Stage: auditv1.StageResponseComplete, Verb: "create", ObjectRef: podRef, RequestObject: pod,
+			UserAgent: "kubectl/v1.16.0", User: authenticationv1.UserInfo{Username: "test-user"}},
+		gvk:       podGVK,
+		operation: "CREATE",
+		test:      "test-user",
+	}}
+
+	for _, data := range datas {
+		t.Run(data.name, func(t *testing.T) {
+			msg, err := a.auditEventMsg(&data.event)
+			if data.skipped {
+				if err == nil {
+					t.Fatalf("Expected the audit event skipped, found: %+v", msg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the audit event recorded, found error: %v", err)
+			}
+			if msg.resourceGVK != data.gvk || msg.operation != data.operation {
+				t.Errorf("Expected coverage update for %v on %s, found: %+v", data.gvk, data.operation, msg)
+			}
+			if !reflect.DeepEqual(msg.subresource, data.subresource) {
+				t.Errorf("Expected subresource %v, found: %v", data.subresource, msg.subresource)
+			}
+			if msg.userAgent != data.event.UserAgent || msg.username != data.event.User.Username {
+				t.Errorf("Expected userAgent %q and username %q, found: %+v", data.event.UserAgent, data.event.User.Username, msg)
+			}
+			if test := testName(nil, msg); len(data.test) != 0 && test != data.test {
+				t.Errorf("Expected coverage attributed to test %q, found: %q", data.test, test)
+			}
+		})
+	}
+}
+
+func auditLogLine(stage auditv1.Stage, verb string, spec string) string {
+	return `{"kind": "Event", "apiVersion": "audit.k8s.io/v1", "stage": "` + string(stage) + `", "verb": "` + verb + `",` +
+		` "objectRef": {"resource": "pods", "namespace": "default", "name": "test", "apiVersion": "v1"},` +
+		` "requestObject": {"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "test"}, "spec": ` + spec + `}}` + "\n"
+}
+
+func TestReplayAuditLog(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap: map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+	}
+	a.ResourceForest.AddResourceTree(ResourceKey(podGVK), podGVK.Kind, a.ResourceMap[podGVK])
+
+	auditLog := auditLogLine(auditv1.StageRequestReceived, "create", `{"hostname": "a"}`) +
+		auditLogLine(auditv1.StageResponseComplete, "create", `{"nodeName": "node-a"}`) +
+		auditLogLine(auditv1.StageResponseComplete, "patch", `{"hostIPC": true}`) +
+		auditLogLine(auditv1.StageResponseComplete, "update", `{"nodeName": "node-b"}`)
+	recorded, err := a.ReplayAuditLog(strings.NewReader(auditLog))
+	if err != nil || recorded != 2 {
+		t.Fatalf("Expected 2 audit events recorded, found: %d, error: %v", recorded, err)
+	}
+
+	malformed, err := a.ReplayAuditLog(strings.NewReader(auditLogLine(auditv1.StageResponseComplete, "create", `{}`) + "{not json\n"))
+	if err == nil || malformed != 1 {
+		t.Errorf("Expected an error after 1 audit event recorded, found: %d, error: %v", malformed, err)
+	}
+
+	_, typeCoverage := a.BuildResourceCoverage(ResourceKey(podGVK), resourcetree.CoverageOptions{})
+	for _, coverage := range typeCoverage {
+		if coverage.Type != "PodSpec" {
+			continue
+		}
+		if nodeName := coverage.Fields["nodeName"]; !nodeName.Coverage || nodeName.Hits != 2 {
+			t.Errorf("Expected PodSpec.nodeName covered by 2 events, found: %+v", nodeName)
+		}
+		// Neither the request received stage nor the patch are recorded
+		if hostname := coverage.Fields["hostname"]; hostname.Coverage {
+			t.Errorf("Expected PodSpec.hostname not covered, found: %+v", hostname)
+		}
+		if hostIPC := coverage.Fields["hostIPC"]; hostIPC.Values.Has("true") {
+			t.Errorf("Expected PodSpec.hostIPC never true, found: %+v", hostIPC)
+		}
+		return
+	}
+	t.Errorf("Expected PodSpec coverage, found: %+v", typeCoverage)
+}
+
+func TestStopRecordsQueuedResources(t *testing.T) {
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	a := &APICoverageRecorder{
+		Logger: zap.NewNop().Sugar(),
+		ResourceForest: resourcetree.ResourceForest{
+			ConnectedNodes: make(map[string]*list.List),
+			TopLevelTrees:  make(map[string]resourcetree.ResourceTree),
+		},
+		ResourceMap:       map[schema.GroupVersionKind]reflect.Type{podGVK: reflect.TypeOf(corev1.Pod{})},
+		IgnoredFieldsFile: "/dev/null",
+	}
+	a.Init()
+
+	for i := 0; i < resourceChannelQueueSize; i++ {
+		a.RecordResourceCoverage(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(strings.Replace(podReviewTemplate, "VERSION", "v1", 1))))
+	}
+	a.Stop()
+
+	_, typeCoverage := a.BuildResourceCoverage(ResourceKey(podGVK), resourcetree.CoverageOptions{})
+	for _, coverage := range typeCoverage {
+		if metadata, ok := coverage.Fields["metadata"]; ok && metadata.Hits == resourceChannelQueueSize {
+			return
+		}
+	}
+	t.Errorf("Expected Pod.metadata covered by all %d resources, found: %+v", resourceChannelQueueSize, typeCoverage)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	auditv1 "k8s.io/apiserver/pkg/apis/audit/v1"
+)
+
+// audit_recorder.go contains an alternate ingestion path for the
+// APICoverageRecorder that consumes audit events instead of AdmissionReviews.
+// Unlike admission webhooks, audit events carry the user agent of the client
+// that made the request, which lets us attribute coverage to a test.
+
+var (
+	// auditOperations maps the verbs of audit events with request objects to
+	// the admission operation of the same request.
+	auditOperations = map[string]string{
+		"create": string(admissionv1.Create),
+		"update": string(admissionv1.Update),
+		"delete": string(admissionv1.Delete),
+	}
+
+	// connectSubresources are the subresources that admission sees CONNECT
+	// requests to, whatever the verb of their audit events.
+	connectSubresources = sets.NewString("attach", "exec", "portforward", "proxy")
+)
+
+const (
+	// AuditEventsEndPoint is the endpoint that accepts audit.k8s.io/v1
+	// EventLists, e.g. as posted by a dynamic audit or webhook audit backend.
+	AuditEventsEndPoint = "/auditevents"
+)
+
+// RecordAuditEvents updates the resource tree with the request objects
+// carried by a batch of audit events.
+func (a *APICoverageRecorder) RecordAuditEvents(w http.ResponseWriter, r *http.Request) {
+	a.Logger.Info("APICoverageRecorder.RecordAuditEvents")
+
+	events := &auditv1.EventList{}
+	if err := a.jsonRead(r, events, "audit events"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for i := range events.Items {
+		msg, err := a.auditEventMsg(&events.Items[i])
+		if err != nil {
+			a.Logger.Infof("By-passing resource coverage update for audit event %s: %v", events.Items[i].AuditID, err)
+			continue
+		}
+		a.Logger.Infof("APICoverageRecorder.RecordAuditEvents sending to channel gvk %v, verb %v, userAgent %q", msg.resourceGVK, events.Items[i].Verb, msg.userAgent)
+		a.resourceChannel <- msg
+	}
+}
+
+// ReplayAuditLog synchronously updates the resource tree with the request
+// objects of newline-delimited audit events read from r, as written by the
+// kube-apiserver log backend in json format. It returns the number of events
+// that were recorded.
+func (a *APICoverageRecorder) ReplayAuditLog(r io.Reader) (int, error) {
+	a.Logger.Info("APICoverageRecorder.ReplayAuditLog")
+
+	recorded := 0
+	decoder := json.NewDecoder(r)
+	for {
+		event := &auditv1.Event{}
+		if err := decoder.Decode(event); err == io.EOF {
+			return recorded, nil
+		} else if err != nil {
+			return recorded, fmt.Errorf("unable to decode audit event: %v", err)
+		}
+		msg, err := a.auditEventMsg(event)
+		if err != nil {
+			a.Logger.Debugf("By-passing resource coverage update for audit event %s: %v", event.AuditID, err)
+			continue
+		}
+		a.updateResourceCoverage(msg)
+		recorded++
+	}
+}
+
+// auditEventMsg builds the resource channel message for the request object of
+// a single audit event, or returns an error explaining why it was skipped.
+func (a *APICoverageRecorder) auditEventMsg(event *auditv1.Event) (resourceChannelMsg, error) {
+	// The request object is logged at every stage, only count it once.
+	if event.Stage != auditv1.StageResponseComplete {
+		return resourceChannelMsg{}, fmt.Errorf("stage is %s", event.Stage)
+	}
+	// Patch bodies are partial objects (or JSON patch operations), so we
+	// can't compute field coverage from them
+	if event.Verb == "patch" {
+		return resourceChannelMsg{}, fmt.Errorf("verb is %s", event.Verb)
+	}
+	if event.RequestObject == nil || len(event.RequestObject.Raw) == 0 {
+		return resourceChannelMsg{}, fmt.Errorf("no request object for verb %s", event.Verb)
+	}
+
+	gvk, err := rawObjectGVK(event.RequestObject.Raw)
+	if err != nil {
+		return resourceChannelMsg{}, err
+	}
+	msg := resourceChannelMsg{
+		resourceGVK:      gvk,
+		rawResourceValue: event.RequestObject.Raw,
+		userAgent:        event.UserAgent,
+		username:         event.User.Username,
+		operation:        auditOperation(event),
+		timestamp:        event.RequestReceivedTimestamp.Time,
+	}
+	if event.ObjectRef != nil {
+		msg.namespace, msg.name, msg.uid = event.ObjectRef.Namespace, event.ObjectRef.Name, string(event.ObjectRef.UID)
+	}
+	// The request objects of deletes are their DeleteOptions
+	if optionsGVK, ok := a.optionsGVK(gvk); ok {
+		msg.resourceGVK = optionsGVK
+		return msg, nil
+	}
+	msg.subresource = a.auditSubresource(event)
+	// We only care about resources the repo has setup.
+	if msg.subresource == nil && !a.isSetup(gvk) {
+		return resourceChannelMsg{}, fmt.Errorf("resource %v is not setup", gvk)
+	}
+	return msg, nil
+}
+
+// auditOperation returns the admission operation of an audit event's request.
+func auditOperation(event *auditv1.Event) string {
+	if event.ObjectRef != nil && connectSubresources.Has(event.ObjectRef.Subresource) {
+		return string(admissionv1.Connect)
+	}
+	return auditOperations[event.Verb]
+}
+
+// auditSubresource returns the subresource an audit event's request was made
+// to, if it is setup in SubresourceMap.
+func (a *APICoverageRecorder) auditSubresource(event *auditv1.Event) *Subresource {
+	if event.ObjectRef == nil || event.ObjectRef.Subresource == "" {
+		return nil
+	}
+	subresource := Subresource{
+		Resource: schema.GroupVersionResource{
+			Group:    event.ObjectRef.APIGroup,
+			Version:  event.ObjectRef.APIVersion,
+			Resource: event.ObjectRef.Resource,
+		},
+		Subresource: event.ObjectRef.Subresource,
+	}
+	if _, ok := a.SubresourceMap[subresource]; !ok {
+		return nil
+	}
+	return &subresource
+}
+
+// rawObjectGVK returns the GroupVersionKind set in the TypeMeta of a raw
+// object, eg: an audit event's request object. Unlike AdmissionRequests, audit
+// events only reference the resource, not the kind, of the object.
+func rawObjectGVK(raw []byte) (schema.GroupVersionKind, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return schema.GroupVersionKind{}, fmt.Errorf("unable to decode request object type: %v", err)
+	}
+	if typeMeta.Kind == "" {
+		return schema.GroupVersionKind{}, fmt.Errorf("request object has no kind")
+	}
+	return schema.FromAPIVersionAndKind(typeMeta.APIVersion, typeMeta.Kind), nil
+}
@@ -26,14 +26,19 @@ import (
 	"strings"
 	"time"
 
-	"github.com/markbates/inflect"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
 	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
 	"knative.dev/pkg/webhook/certificates/resources"
 	"sigs.k8s.io/k8s-api-coverage/pkg/kube"
 )
@@ -41,6 +46,10 @@ import (
 var (
 	// GroupVersionKind for deployment to be used to set the webhook's owner reference.
 	deploymentKind = extensionsv1beta1.SchemeGroupVersion.WithKind("Deployment")
+
+	// AdmissionReview versions RecordResourceCoverage understands, in order
+	// of preference.
+	admissionReviewVersions = []string{admissionv1.SchemeGroupVersion.Version, admissionv1beta1.SchemeGroupVersion.Version}
 )
 
 const (
@@ -79,13 +88,18 @@ type APICoverageWebhook struct {
 	CaCert []byte
 
 	// FailurePolicy policy governs the webhook validation decisions.
-	FailurePolicy admissionregistrationv1beta1.FailurePolicyType
+	FailurePolicy admissionregistrationv1.FailurePolicyType
 
 	// Logger is the configured logger for the webhook.
 	Logger *zap.SugaredLogger
 
 	// KubeClient is the K8 client to the target cluster.
 	KubeClient kubernetes.Interface
+
+	// registered is the ValidatingWebhookConfiguration, of whichever
+	// admissionregistration version the API server serves, created or adopted
+	// by registerWebhook, so we only ever delete our own.
+	registered metav1.Object
 }
 
 func (acw *APICoverageWebhook) generateServerConfig() (*tls.Config, error) {
@@ -119,69 +133,193 @@ func (acw *APICoverageWebhook) getWebhookServer(handler http.Handler) (*http.Ser
 	}, nil
 }
 
-func (acw *APICoverageWebhook) registerWebhook(rules []admissionregistrationv1beta1.RuleWithOperations, namespace string) error {
+func (acw *APICoverageWebhook) registerWebhook(rules []admissionregistrationv1.RuleWithOperations, namespace string) error {
 	acw.Logger.Info("APICoverageRecorder.registerWebhook")
-	webhook := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+	deployment, err := acw.KubeClient.AppsV1().Deployments(namespace).Get(acw.DeploymentName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("Error retrieving Deployment Extension object: %v", err)
+	}
+	deploymentRef := metav1.NewControllerRef(deployment, deploymentKind)
+
+	servesV1, err := acw.servesAdmissionRegistrationV1()
+	if err != nil {
+		return err
+	}
+	if !servesV1 {
+		acw.Logger.Infof("%s is not served, falling back to %s", admissionregistrationv1.SchemeGroupVersion, admissionregistrationv1beta1.SchemeGroupVersion)
+		return acw.registerWebhookV1beta1(rules, namespace, deployment, deploymentRef)
+	}
+
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	// Unlike v1beta1, v1 defaults to sending us requests converted to the
+	// version in our rules; we want coverage of the versions that were sent
+	matchPolicy := admissionregistrationv1.Exact
+	webhook := &admissionregistrationv1.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      acw.WebhookName,
-			Namespace: namespace,
+			Name:            acw.WebhookName,
+			OwnerReferences: []metav1.OwnerReference{*deploymentRef},
 		},
-		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{{
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
 			Name:  acw.WebhookName,
 			Rules: rules,
-			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
-				Service: &admissionregistrationv1beta1.ServiceReference{
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
 					Namespace: namespace,
 					Name:      acw.ServiceName,
 				},
 				CABundle: acw.CaCert,
 			},
-			FailurePolicy: &acw.FailurePolicy,
+			FailurePolicy:           &acw.FailurePolicy,
+			MatchPolicy:             &matchPolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: admissionReviewVersions,
 		},
 		},
 	}
 
-	deployment, err := acw.KubeClient.AppsV1().Deployments(namespace).Get(acw.DeploymentName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("Error retrieving Deployment Extension object: %v", err)
+	client := acw.KubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	existing, err := client.Get(acw.WebhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		created, err := client.Create(webhook)
+		if err != nil {
+			return fmt.Errorf("Error creating ValidatingWebhookConfigurations object: %v", err)
+		}
+		acw.registered = created
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Error retrieving ValidatingWebhookConfigurations object: %v", err)
 	}
-	deploymentRef := metav1.NewControllerRef(deployment, deploymentKind)
-	webhook.OwnerReferences = append(webhook.OwnerReferences, *deploymentRef)
 
-	// TODO(spiffxp): seems like this should either delete a pre-existing
-	// webhook, or ignore an existing webhook if it matches the same
-	// deploymentRef we just tried to set
-	_, err = acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Create(webhook)
+	if err := acw.checkAdoptable(existing, deployment); err != nil {
+		return err
+	}
+	// Refresh the rules, and the CABundle for the certs we just generated
+	existing.Webhooks = webhook.Webhooks
+	updated, err := client.Update(existing)
 	if err != nil {
-		return fmt.Errorf("Error creating ValidatingWebhookConfigurations object: %v", err)
+		return fmt.Errorf("Error updating ValidatingWebhookConfigurations object: %v", err)
+	}
+	acw.registered = updated
+	return nil
+}
+
+// servesAdmissionRegistrationV1 returns whether the API server serves
+// admissionregistration.k8s.io/v1, which replaces v1beta1 as of kubernetes 1.16.
+// Only a NotFound means it isn't served, other errors are returned.
+func (acw *APICoverageWebhook) servesAdmissionRegistrationV1() (bool, error) {
+	_, err := acw.KubeClient.Discovery().ServerResourcesForGroupVersion(admissionregistrationv1.SchemeGroupVersion.String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("Error discovering %s: %v", admissionregistrationv1.SchemeGroupVersion, err)
 	}
+	return true, nil
+}
 
+// checkAdoptable returns an error unless an existing webhook configuration
+// was left behind by a previous run of this deployment (eg: after a crash).
+// Anything else is not ours to touch.
+func (acw *APICoverageWebhook) checkAdoptable(existing metav1.Object, deployment metav1.Object) error {
+	if !metav1.IsControlledBy(existing, deployment) {
+		return fmt.Errorf("ValidatingWebhookConfigurations object %s already exists and is not owned by deployment %s/%s", acw.WebhookName, deployment.GetNamespace(), acw.DeploymentName)
+	}
+	return nil
+}
+
+func (acw *APICoverageWebhook) unregisterWebhook() error {
+	acw.Logger.Info("APICoverageRecorder.unregisterWebhook")
+	if acw.registered == nil {
+		return nil
+	}
+
+	options := &metav1.DeleteOptions{}
+	if len(acw.registered.GetUID()) != 0 {
+		options.Preconditions = metav1.NewUIDPreconditions(string(acw.registered.GetUID()))
+	}
+	var err error
+	switch acw.registered.(type) {
+	case *admissionregistrationv1beta1.ValidatingWebhookConfiguration:
+		err = acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Delete(acw.registered.GetName(), options)
+	default:
+		err = acw.KubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(acw.registered.GetName(), options)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("Error deleting ValidatingWebhookConfigurations object: %v", err)
+	}
+	acw.registered = nil
 	return nil
 }
 
-func (acw *APICoverageWebhook) getValidationRules(gvks []schema.GroupVersionKind) []admissionregistrationv1beta1.RuleWithOperations {
-	var rules []admissionregistrationv1beta1.RuleWithOperations
+// getValidationRules returns a rule for each of gvks that the API server serves
+// as a resource, covering the resource and all of its subresources. Resource
+// names come from discovery, as kinds don't pluralize predictably (eg:
+// Endpoints), and kinds that aren't served as resources (eg: PodList,
+// DeleteOptions) are skipped.
+func (acw *APICoverageWebhook) getValidationRules(gvks []schema.GroupVersionKind) ([]admissionregistrationv1.RuleWithOperations, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(acw.KubeClient.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("Error discovering API resources: %v", err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	served := getServedResources(groupResources)
+
+	var rules []admissionregistrationv1.RuleWithOperations
 	for _, gvk := range gvks {
-		resourcePlural := strings.ToLower(inflect.Pluralize(gvk.Kind))
-		rules = append(rules, admissionregistrationv1beta1.RuleWithOperations{
-			Operations: []admissionregistrationv1beta1.OperationType{
-				admissionregistrationv1beta1.Create,
-				admissionregistrationv1beta1.Update,
-				admissionregistrationv1beta1.Connect,
-				// We ignore DELETE because no body is sent along, so we have
-				// nothing with which to compute field coverage
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			acw.Logger.Debugf("Skipping %s, it is not served as a resource", gvk)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("Error mapping %s to a resource: %v", gvk, err)
+		}
+		// The RESTMapper guesses a resource for list kinds, eg: podlists for
+		// PodList, so also check the resource is actually served
+		subresources, ok := served[mapping.Resource]
+		if !ok {
+			acw.Logger.Debugf("Skipping %s, it is not served as a resource", gvk)
+			continue
+		}
+		resources := append([]string{mapping.Resource.Resource}, subresources...)
+		rules = append(rules, admissionregistrationv1.RuleWithOperations{
+			Operations: []admissionregistrationv1.OperationType{
+				admissionregistrationv1.Create,
+				admissionregistrationv1.Update,
+				admissionregistrationv1.Delete,
+				admissionregistrationv1.Connect,
 			},
-			Rule: admissionregistrationv1beta1.Rule{
+			Rule: admissionregistrationv1.Rule{
 				APIGroups:   []string{gvk.Group},
 				APIVersions: []string{gvk.Version},
-				Resources:   []string{resourcePlural, resourcePlural + "/*"}, // we also want all subresources, if any exist
+				Resources:   resources,
 			},
 		})
 	}
-	return rules
+	return rules, nil
+}
+
+// getServedResources returns the resources served by the API server, with the
+// names of their subresources, eg: pods/log and pods/exec for pods, which the
+// RESTMapper omits.
+func getServedResources(groupResources []*restmapper.APIGroupResources) map[schema.GroupVersionResource][]string {
+	served := make(map[schema.GroupVersionResource][]string)
+	for _, group := range groupResources {
+		for version, resources := range group.VersionedResources {
+			for _, resource := range resources {
+				parts := strings.SplitN(resource.Name, "/", 2)
+				gvr := schema.GroupVersionResource{Group: group.Group.Name, Version: version, Resource: parts[0]}
+				subresources := served[gvr]
+				if len(parts) == 2 {
+					subresources = append(subresources, resource.Name)
+				}
+				served[gvr] = subresources
+			}
+		}
+	}
+	return served
 }
 
 // Run sets up the webhook with the provided http.handler, resourcegroup Map, namespace and stop channel.
+// The webhook is unregistered once stop is closed.
 func (acw *APICoverageWebhook) Run(handler http.Handler, resources []schema.GroupVersionKind, namespace string, stop <-chan struct{}) error {
 	acw.Logger.Info("APICoverageWebhook.Run")
 	server, err := acw.getWebhookServer(handler)
@@ -191,7 +329,10 @@ func (acw *APICoverageWebhook) Run(handler http.Handler, resources []schema.Grou
 
 	select {
 	case <-time.After(acw.RegistrationDelay):
-		rules := acw.getValidationRules(resources)
+		rules, err := acw.getValidationRules(resources)
+		if err != nil {
+			return fmt.Errorf("Webhook registration failed: %v", err)
+		}
 		err = acw.registerWebhook(rules, namespace)
 		if err != nil {
 			return fmt.Errorf("Webhook registration failed: %v", err)
@@ -214,10 +355,17 @@ func (acw *APICoverageWebhook) Run(handler http.Handler, resources []schema.Grou
 
 	select {
 	case <-stop:
-		return server.Close()
+		err = server.Close()
 	case <-serverBootstrapErrCh:
-		return errors.New("webhook server bootstrap failed")
+		err = errors.New("webhook server bootstrap failed")
+	}
+
+	if unregisterErr := acw.unregisterWebhook(); unregisterErr != nil {
+		acw.Logger.Errorf("Webhook unregistration failed: %v", unregisterErr)
+	} else {
+		acw.Logger.Infof("Unregistered webhook %s/%s", namespace, acw.WebhookName)
 	}
+	return err
 }
 
 func buildLogger(name string, level string) (*zap.SugaredLogger, error) {
@@ -257,7 +405,7 @@ func BuildWebhookConfiguration(componentCommonName string, namespace string) *AP
 	return &APICoverageWebhook{
 		Logger:            logger,
 		KubeClient:        kubeClient,
-		FailurePolicy:     admissionregistrationv1beta1.Ignore, // TODO(spiffxp): this was Fail, I think it should be Ignore, or at least it needs to be while I debug
+		FailurePolicy:     admissionregistrationv1.Ignore, // TODO(spiffxp): this was Fail, I think it should be Ignore, or at least it needs to be while I debug
 		ClientAuth:        tls.NoClientCert,
 		RegistrationDelay: time.Second * 2,
 		Port:              webhookPort,
@@ -0,0 +1,257 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "test"
+
+// testDiscovery is a FakeDiscovery that, like API servers, responds NotFound
+// for group versions it doesn't serve, or err if set.
+type testDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	err error
+}
+
+func (d *testDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	resources, err := d.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+	if err != nil {
+		return nil, apierrors.NewNotFound(schema.GroupResource{}, groupVersion)
+	}
+	return resources, nil
+}
+
+// testClientset is a fake Clientset whose Discovery is a testDiscovery.
+type testClientset struct {
+	*fake.Clientset
+	discovery *testDiscovery
+}
+
+func (c *testClientset) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
+func getTestWebhook(t *testing.T) *APICoverageWebhook {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apicoverage-webhook",
+			Namespace: testNamespace,
+			UID:       "deployment-uid",
+		},
+	}
+	clientset := fake.NewSimpleClientset(deployment)
+	kubeClient := &testClientset{Clientset: clientset, discovery: &testDiscovery{FakeDiscovery: clientset.Discovery().(*fakediscovery.FakeDiscovery)}}
+	kubeClient.discovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true},
+			{Name: "pods/exec", Kind: "PodExecOptions", Namespaced: true},
+			{Name: "pods/log", Kind: "Pod", Namespaced: true},
+			{Name: "endpoints", Kind: "Endpoints", Namespaced: true},
+			{Name: "services", Kind: "Service", Namespaced: true},
+		},
+	}, {
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			{Name: "deployments/scale", Group: "autoscaling", Version: "v1", Kind: "Scale", Namespaced: true},
+		},
+	}}
+	return &APICoverageWebhook{
+		WebhookName:    "apicoverage-webhook.k8s.io",
+		ServiceName:    "apicoverage-webhook",
+		DeploymentName: deployment.Name,
+		Namespace:      testNamespace,
+		CaCert:         []byte("first-ca"),
+		FailurePolicy:  admissionregistrationv1.Ignore,
+		Logger:         zap.NewNop().Sugar(),
+		KubeClient:     kubeClient,
+	}
+}
+
+func getWebhookConfiguration(t *testing.T, acw *APICoverageWebhook) *admissionregistrationv1beta1.ValidatingWebhookConfiguration {
+	webhook, err := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error retrieving webhook configuration: %v", err)
+	}
+	return webhook
+}
+
+func TestRegisterWebhookCreatesOrUpdates(t *testing.T) {
+	acw := getTestWebhook(t)
+	podRules, err := acw.getValidationRules([]schema.GroupVersionKind{{Version: "v1", Kind: "Pod"}})
+	if err != nil {
+		t.Fatalf("Error getting validation rules: %v", err)
+	}
+	if err := acw.registerWebhook(podRules, testNamespace); err != nil {
+		t.Fatalf("Error registering webhook: %v", err)
+	}
+
+	// Simulate a restart after a crash: new certs, new rules, same deployment.
+	acw.registered = nil
+	acw.CaCert = []byte("second-ca")
+	serviceRules, err := acw.getValidationRules([]schema.GroupVersionKind{{Version: "v1", Kind: "Service"}})
+	if err != nil {
+		t.Fatalf("Error getting validation rules: %v", err)
+	}
+	if err := acw.registerWebhook(serviceRules, testNamespace); err != nil {
+		t.Fatalf("Error registering webhook a second time: %v", err)
+	}
+
+	webhook := getWebhookConfiguration(t, acw)
+	if len(webhook.Webhooks) != 1 {
+		t.Fatalf("Expected 1 webhook, found: %d", len(webhook.Webhooks))
+	}
+	if !bytes.Equal(webhook.Webhooks[0].ClientConfig.CABundle, acw.CaCert) {
+		t.Errorf("Expected CABundle %q, found: %q", acw.CaCert, webhook.Webhooks[0].ClientConfig.CABundle)
+	}
+	if resources := webhook.Webhooks[0].Rules[0].Resources; resources[0] != "services" {
+		t.Errorf("Expected rules for services, found: %v", resources)
+	}
+}
+
+func TestGetValidationRules(t *testing.T) {
+	acw := getTestWebhook(t)
+	rules, err := acw.getValidationRules([]schema.GroupVersionKind{
+		{Version: "v1", Kind: "Pod"},
+		{Version: "v1", Kind: "PodList"},
+		{Version: "v1", Kind: "Endpoints"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apps", Version: "v1", Kind: "DeleteOptions"},
+		{Group: "example.com", Version: "v1", Kind: "Foo"},
+	})
+	if err != nil {
+		t.Fatalf("Error getting validation rules: %v", err)
+	}
+
+	// PodList, DeleteOptions and the unserved Foo get no rules
+	expected := [][]string{
+		{"pods", "pods/exec", "pods/log"},
+		{"endpoints"},
+		{"deployments", "deployments/scale"},
+	}
+	if len(rules) != len(expected) {
+		t.Fatalf("Expected %d rules, found: %+v", len(expected), rules)
+	}
+	for i, rule := range rules {
+		if !reflect.DeepEqual(rule.Resources, expected[i]) {
+			t.Errorf("Expected resources %v, found: %v", expected[i], rule.Resources)
+		}
+	}
+}
+
+func TestRegisterWebhookNotOwned(t *testing.T) {
+	acw := getTestWebhook(t)
+	existing := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: acw.WebhookName,
+		},
+	}
+	if _, err := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := acw.registerWebhook(nil, testNamespace); err == nil {
+		t.Fatal("Expected an error registering over a webhook configuration owned by someone else")
+	}
+
+	// Failing to register must not remove someone else's configuration
+	if err := acw.unregisterWebhook(); err != nil {
+		t.Fatalf("Error unregistering webhook: %v", err)
+	}
+	getWebhookConfiguration(t, acw)
+}
+
+func TestUnregisterWebhook(t *testing.T) {
+	acw := getTestWebhook(t)
+	if err := acw.registerWebhook(nil, testNamespace); err != nil {
+		t.Fatalf("Error registering webhook: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := acw.unregisterWebhook(); err != nil {
+			t.Fatalf("Error unregistering webhook: %v", err)
+		}
+	}
+
+	_, err := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected webhook configuration to be deleted, found error: %v", err)
+	}
+}
+
+func TestRegisterWebhookV1(t *testing.T) {
+	acw := getTestWebhook(t)
+	discoveryClient := acw.KubeClient.(*testClientset).discovery
+	discoveryClient.Resources = append(discoveryClient.Resources, &metav1.APIResourceList{
+		GroupVersion: admissionregistrationv1.SchemeGroupVersion.String(),
+	})
+	if err := acw.registerWebhook(nil, testNamespace); err != nil {
+		t.Fatalf("Error registering webhook: %v", err)
+	}
+
+	webhook, err := acw.KubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Error retrieving webhook configuration: %v", err)
+	}
+	if sideEffects := webhook.Webhooks[0].SideEffects; sideEffects == nil || *sideEffects != admissionregistrationv1.SideEffectClassNone {
+		t.Errorf("Expected sideEffects %s, found: %v", admissionregistrationv1.SideEffectClassNone, sideEffects)
+	}
+	if versions := webhook.Webhooks[0].AdmissionReviewVersions; len(versions) != 2 || versions[0] != "v1" {
+		t.Errorf("Expected admissionReviewVersions [v1 v1beta1], found: %v", versions)
+	}
+	if _, err := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected no v1beta1 webhook configuration, found error: %v", err)
+	}
+
+	if err := acw.unregisterWebhook(); err != nil {
+		t.Fatalf("Error unregistering webhook: %v", err)
+	}
+	if _, err := acw.KubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("Expected webhook configuration to be deleted, found error: %v", err)
+	}
+}
+
+func TestRegisterWebhookDiscoveryError(t *testing.T) {
+	acw := getTestWebhook(t)
+	acw.KubeClient.(*testClientset).discovery.err = apierrors.NewForbidden(admissionregistrationv1.Resource(""), "", nil)
+	if err := acw.registerWebhook(nil, testNamespace); err == nil {
+		t.Fatal("Expected an error registering webhook when discovery is forbidden")
+	}
+
+	// Only a NotFound falls back to v1beta1
+	if _, err := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(acw.WebhookName, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected no v1beta1 webhook configuration, found error: %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// webhook_v1beta1.go registers the webhook through admissionregistration.k8s.io/v1beta1,
+// for API servers older than kubernetes 1.16 that don't serve v1.
+
+func (acw *APICoverageWebhook) registerWebhookV1beta1(rules []admissionregistrationv1.RuleWithOperations, namespace string, deployment *appsv1.Deployment, deploymentRef *metav1.OwnerReference) error {
+	failurePolicy := admissionregistrationv1beta1.FailurePolicyType(acw.FailurePolicy)
+	sideEffects := admissionregistrationv1beta1.SideEffectClassNone
+	webhook := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            acw.WebhookName,
+			OwnerReferences: []metav1.OwnerReference{*deploymentRef},
+		},
+		Webhooks: []admissionregistrationv1beta1.ValidatingWebhook{{
+			Name:  acw.WebhookName,
+			Rules: toV1beta1Rules(rules),
+			ClientConfig: admissionregistrationv1beta1.WebhookClientConfig{
+				Service: &admissionregistrationv1beta1.ServiceReference{
+					Namespace: namespace,
+					Name:      acw.ServiceName,
+				},
+				CABundle: acw.CaCert,
+			},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: admissionReviewVersions,
+		},
+		},
+	}
+
+	client := acw.KubeClient.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existing, err := client.Get(acw.WebhookName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		created, err := client.Create(webhook)
+		if err != nil {
+			return fmt.Errorf("Error creating ValidatingWebhookConfigurations object: %v", err)
+		}
+		acw.registered = created
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Error retrieving ValidatingWebhookConfigurations object: %v", err)
+	}
+
+	if err := acw.checkAdoptable(existing, deployment); err != nil {
+		return err
+	}
+	// Refresh the rules, and the CABundle for the certs we just generated
+	existing.Webhooks = webhook.Webhooks
+	updated, err := client.Update(existing)
+	if err != nil {
+		return fmt.Errorf("Error updating ValidatingWebhookConfigurations object: %v", err)
+	}
+	acw.registered = updated
+	return nil
+}
+
+func toV1beta1Rules(rules []admissionregistrationv1.RuleWithOperations) []admissionregistrationv1beta1.RuleWithOperations {
+	var v1beta1Rules []admissionregistrationv1beta1.RuleWithOperations
+	for _, rule := range rules {
+		var operations []admissionregistrationv1beta1.OperationType
+		for _, op := range rule.Operations {
+			operations = append(operations, admissionregistrationv1beta1.OperationType(op))
+		}
+		v1beta1Rule := admissionregistrationv1beta1.RuleWithOperations{
+			Operations: operations,
+			Rule: admissionregistrationv1beta1.Rule{
+				APIGroups:   rule.APIGroups,
+				APIVersions: rule.APIVersions,
+				Resources:   rule.Resources,
+			},
+		}
+		if rule.Scope != nil {
+			scope := admissionregistrationv1beta1.ScopeType(*rule.Scope)
+			v1beta1Rule.Scope = &scope
+		}
+		v1beta1Rules = append(v1beta1Rules, v1beta1Rule)
+	}
+	return v1beta1Rules
+}